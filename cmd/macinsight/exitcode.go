@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/samuraidays/macinsight/pkg/types"
+)
+
+// プロセス終了コード
+const (
+	ExitOK         = 0 // すべて合格（ポリシー違反・実行エラーなし）
+	ExitPolicy     = 1 // --fail-on / --min-score のポリシー違反
+	ExitUsage      = 2 // フラグ・設定ファイルなどの指定誤り
+	ExitCheckError = 3 // 判定できなかったチェック（unknown / skipped / cancelled）がある
+	ExitInternal   = 4 // 出力の書き込み失敗などの内部エラー
+)
+
+// --fail-on の値（config.FailOnLevels）ごとに違反とみなすステータス
+var failOnStatuses = map[string][]string{
+	"fail":    {"fail"},
+	"warn":    {"fail", "warn"},
+	"unknown": {"fail", "warn", "unknown", "skipped"},
+}
+
+// レポートを評価して終了コードと理由を返す
+func evaluateExit(rep types.Report, failOn string, minScore int) (int, string) {
+	var reasons []string
+
+	if statuses, ok := failOnStatuses[failOn]; ok {
+		var ids []string
+		for _, c := range rep.Checks {
+			for _, s := range statuses {
+				if c.Status == s {
+					ids = append(ids, c.ID)
+					break
+				}
+			}
+		}
+		if len(ids) > 0 {
+			reasons = append(reasons, fmt.Sprintf("%d check(s) at or above %q: %s", len(ids), failOn, strings.Join(ids, ", ")))
+		}
+	}
+	if minScore > 0 && rep.Score < minScore {
+		reasons = append(reasons, fmt.Sprintf("score %d is below --min-score %d", rep.Score, minScore))
+	}
+	if len(reasons) > 0 {
+		return ExitPolicy, "policy violation: " + strings.Join(reasons, "; ")
+	}
+
+	var errored []string
+	for _, c := range rep.Checks {
+		// skipped（権限不足など）も評価できていないため、合格扱いにしない
+		if c.Status == "unknown" || c.Status == "skipped" || c.Status == "cancelled" {
+			errored = append(errored, c.ID)
+		}
+	}
+	if len(errored) > 0 {
+		return ExitCheckError, "checks errored: " + strings.Join(errored, ", ")
+	}
+	return ExitOK, ""
+}
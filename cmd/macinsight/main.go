@@ -1,15 +1,30 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"os"
+	"os/signal"
+	"runtime"
+	"sort"
 	"strings"
+	"syscall"
+	"text/tabwriter"
+	"text/template"
 	"time"
 
+	"github.com/samuraidays/macinsight/internal/checks"
+	"github.com/samuraidays/macinsight/internal/config"
+	"github.com/samuraidays/macinsight/internal/executil"
 	"github.com/samuraidays/macinsight/internal/output"
+	"github.com/samuraidays/macinsight/internal/plugin"
+	"github.com/samuraidays/macinsight/internal/profile"
+	"github.com/samuraidays/macinsight/internal/rules"
 	"github.com/samuraidays/macinsight/internal/runner"
 	"github.com/samuraidays/macinsight/internal/schema"
+	"github.com/samuraidays/macinsight/internal/waiver"
+	"github.com/samuraidays/macinsight/pkg/types"
 )
 
 // ldflags で埋め込む用（go build -ldflags "-X main.version=v0.1.0"）
@@ -21,12 +36,18 @@ func main() {
 		return
 	}
 
-	// サブコマンド：audit / list-checks / version / schema
+	// サブコマンド：audit / config / profiles / list-checks / list-commands / version / schema
 	switch os.Args[1] {
 	case "audit":
 		runAudit(os.Args[2:])
+	case "config":
+		runConfig(os.Args[2:])
+	case "profiles":
+		runProfiles(os.Args[2:])
 	case "list-checks":
-		fmt.Println("sip,gatekeeper,filevault,firewall,autologin,osupdate")
+		runListChecks(os.Args[2:])
+	case "list-commands":
+		runListCommands(os.Args[2:])
 	case "version":
 		fmt.Println(version)
 	case "schema":
@@ -40,53 +61,536 @@ func usage() {
 	fmt.Print(`macinsight - macOS Security Audit CLI
 
 Usage:
-  macinsight audit [--json] [--only <checks>] [--exclude <checks>] [--timeout 3s]
-  macinsight list-checks
+  macinsight audit [--config <file>] [--format table|json|ndjson|sarif|junit|html|markdown] [--json] [--template <file>] [--sort id|severity|status|score]
+                   [--only <checks>] [--exclude <checks>]
+                   [--timeout 3s] [--deadline 30s] [--parallel N] [--unknown partial|fail|exclude]
+                   [--fail-on fail|warn|unknown] [--min-score N] [--waivers <file>]
+                   [--profile <name|file>] [--require-root]
+                   [--rules <dirs>] [--plugins <dirs>] [--plugins-path]
+                   [--record <dir> | --replay <dir>] [--trace <file>] [--output <file>] [--debug]
+  macinsight config show [--config <file>] [audit flags]
+  macinsight profiles list
+  macinsight profiles show <name|file>
+  macinsight list-checks [--rules <dirs>] [--plugins <dirs>] [--plugins-path]
+  macinsight list-commands [--rules <dirs>] [--plugins <dirs>] [--plugins-path]
   macinsight version
-  macinsight schema [--output <file>]
+  macinsight schema [--output <file>] [--rules <dirs>] [--plugins <dirs>] [--plugins-path]
+
+Exit codes (audit):
+  0  all checks passed the policy
+  1  policy violation (--fail-on / --min-score)
+  2  usage error (invalid flags or config)
+  3  some checks could not be evaluated (unknown / skipped / cancelled)
+  4  internal error (e.g. failed to write output)
 
 Examples:
   macinsight audit
   macinsight audit --json --only filevault,gatekeeper
+  macinsight audit --fail-on warn --min-score 80
+  macinsight audit --profile cis-l1
+  macinsight audit --deadline 30s --parallel 4
+  sudo macinsight audit --require-root
+  macinsight audit --rules ./examples/rules
+  macinsight audit --record ./fixtures/my-mac
+  macinsight audit --replay ./fixtures/my-mac --json
+  macinsight audit --trace ./macinsight-trace.jsonl
+  macinsight audit --format html --output report.html
+  macinsight audit --format markdown --only filevault,sip > audit.md
+  macinsight audit --template ./examples/templates/checks.csv.tmpl --output checks.csv
+  macinsight config show --config ./macinsight.yaml
   macinsight schema --output schema.json
 `)
 }
 
+// audit 系のフラグ（config show と共有）
+type auditFlags struct {
+	config      string
+	asJSON      bool
+	format      string
+	template    string
+	sort        string
+	only        string
+	exclude     string
+	unknown     string
+	failOn      string
+	minScore    int
+	waivers     string
+	profile     string
+	requireRoot bool
+	timeout     time.Duration
+	deadline    time.Duration
+	parallel    int
+	ext         *extensionFlags
+}
+
+// ルール/プラグインの指定（audit・list-checks・list-commands・schema で共有）
+type extensionFlags struct {
+	rules       string
+	plugins     string
+	pluginsPath bool
+}
+
+func registerExtensionFlags(fs *flag.FlagSet) *extensionFlags {
+	e := &extensionFlags{}
+	fs.StringVar(&e.rules, "rules", "", "comma-separated directories of rule files")
+	fs.StringVar(&e.plugins, "plugins", "", "comma-separated directories of plugin executables")
+	fs.BoolVar(&e.pluginsPath, "plugins-path", false, "also use "+plugin.PathPrefix+"* executables found on PATH")
+	return e
+}
+
+func registerAuditFlags(fs *flag.FlagSet) *auditFlags {
+	f := &auditFlags{}
+	fs.StringVar(&f.config, "config", "", "config file (default: $XDG_CONFIG_HOME/macinsight/config.yaml)")
+	fs.BoolVar(&f.asJSON, "json", false, "print JSON (same as --format json)")
+	fs.StringVar(&f.format, "format", "", "output format: "+strings.Join(output.Formats, "|"))
+	fs.StringVar(&f.template, "template", "", "render the report with this Go text/template file (overrides --format)")
+	fs.StringVar(&f.sort, "sort", "", "order of checks in the output: "+strings.Join(output.SortKeys, "|")+" (default registry/profile order)")
+	fs.StringVar(&f.only, "only", "", "comma-separated checks to include")
+	fs.StringVar(&f.exclude, "exclude", "", "comma-separated checks to skip")
+	fs.StringVar(&f.unknown, "unknown", "", "how to score unknown results: partial|fail|exclude")
+	fs.DurationVar(&f.timeout, "timeout", 0, "per-check timeout (default 3s)")
+	fs.DurationVar(&f.deadline, "deadline", 0, "time limit for the whole audit; unfinished checks are reported as cancelled (default none)")
+	fs.IntVar(&f.parallel, "parallel", 0, "maximum number of checks run concurrently (default unlimited)")
+	fs.StringVar(&f.failOn, "fail-on", "", "exit 1 if any check is at or above this status: "+strings.Join(config.FailOnLevels, "|"))
+	fs.IntVar(&f.minScore, "min-score", 0, "exit 1 if the score is below this value (0-100)")
+	fs.StringVar(&f.waivers, "waivers", "", "waivers (exceptions) file")
+	fs.StringVar(&f.profile, "profile", "", "benchmark profile name or file (see: macinsight profiles list)")
+	fs.BoolVar(&f.requireRoot, "require-root", false, "refuse to run if checks that need root would be skipped")
+	f.ext = registerExtensionFlags(fs)
+	return f
+}
+
+// 設定ファイルを読み込み、明示されたフラグで上書きした実効設定を返す
+func (f *auditFlags) effectiveConfig(fs *flag.FlagSet) (config.Config, error) {
+	cfg, err := config.Load(f.config)
+	if err != nil {
+		return cfg, err
+	}
+
+	fs.Visit(func(fl *flag.Flag) {
+		switch fl.Name {
+		case "json":
+			if f.asJSON {
+				cfg.Format = "json"
+			}
+		case "format":
+			cfg.Format = f.format
+		case "template":
+			cfg.Template = f.template
+		case "sort":
+			cfg.Sort = f.sort
+		case "only":
+			cfg.Only = setKeys(toSet(f.only))
+		case "exclude":
+			cfg.Exclude = setKeys(toSet(f.exclude))
+		case "unknown":
+			cfg.UnknownPolicy = f.unknown
+		case "timeout":
+			cfg.Timeout = f.timeout
+		case "deadline":
+			cfg.Deadline = f.deadline
+		case "parallel":
+			cfg.Parallel = f.parallel
+		case "fail-on":
+			cfg.FailOn = f.failOn
+		case "min-score":
+			cfg.MinScore = f.minScore
+		case "waivers":
+			cfg.Waivers = f.waivers
+		case "profile":
+			cfg.Profile = f.profile
+		case "require-root":
+			cfg.RequireRoot = f.requireRoot
+		case "rules":
+			cfg.Rules = splitList(f.ext.rules)
+		case "plugins":
+			cfg.Plugins = splitList(f.ext.plugins)
+		case "plugins-path":
+			cfg.PluginsPath = f.ext.pluginsPath
+		}
+	})
+
+	return cfg, cfg.Validate()
+}
+
 func runAudit(args []string) {
 	// フラグ定義
 	fs := flag.NewFlagSet("audit", flag.ExitOnError)
-	var asJSON bool
-	var only, exclude string
-	var timeout time.Duration
-	fs.BoolVar(&asJSON, "json", false, "print JSON")
-	fs.StringVar(&only, "only", "", "comma-separated checks to include")
-	fs.StringVar(&exclude, "exclude", "", "comma-separated checks to skip")
-	fs.DurationVar(&timeout, "timeout", 3*time.Second, "per-check timeout")
+	flags := registerAuditFlags(fs)
+	var recordDir, replayDir, traceFile, outputFile string
+	var debug bool
+	fs.StringVar(&recordDir, "record", "", "record command output into a fixture bundle directory")
+	fs.StringVar(&replayDir, "replay", "", "replay command output from a fixture bundle instead of executing")
+	fs.StringVar(&traceFile, "trace", "", "write every executed command to this file as JSON Lines")
+	fs.StringVar(&outputFile, "output", "", "write the report to this file instead of stdout")
+	fs.BoolVar(&debug, "debug", false, "print debug information (command cache hits) to stderr")
 	_ = fs.Parse(args)
 
+	cfg, err := flags.effectiveConfig(fs)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(ExitUsage)
+	}
+	if err := registerExtensions(cfg.Rules, cfg.Plugins, cfg.PluginsPath); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(ExitUsage)
+	}
+	if err := cfg.ValidateChecks(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(ExitUsage)
+	}
+	if recordDir != "" && replayDir != "" {
+		fmt.Fprintln(os.Stderr, "--record and --replay cannot be used together")
+		os.Exit(ExitUsage)
+	}
+
 	// 実行オプションを作成
 	opt := runner.Option{
-		Only:    toSet(only),
-		Exclude: toSet(exclude),
-		Timeout: timeout,
+		Only:    toSet(strings.Join(cfg.Only, ",")),
+		Exclude: toSet(strings.Join(cfg.Exclude, ",")),
+		Timeout: cfg.Timeout,
+
+		Parallel:      cfg.Parallel,
+		Timeouts:      cfg.Timeouts,
+		Weights:       cfg.Weights,
+		Params:        cfg.Params,
+		UnknownPolicy: cfg.UnknownPolicy,
+		Root:          os.Geteuid() == 0,
+	}
+	if debug {
+		opt.Debug = os.Stderr
 	}
 
-	// 監査の実行
-	rep := runner.Run(version, opt)
+	if cfg.Profile != "" {
+		p, err := profile.Get(cfg.Profile)
+		if err == nil {
+			err = p.Validate()
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(ExitUsage)
+		}
+		opt.Profile = p
+	}
 
-	// 出力モード
-	var err error
-	if asJSON {
-		err = output.WriteJSON(os.Stdout, rep)
+	if cfg.Waivers != "" {
+		ws, err := waiver.Load(cfg.Waivers)
+		if err == nil {
+			err = waiver.Validate(ws)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(ExitUsage)
+		}
+		opt.Waivers = ws
+	}
+
+	// 記録/再生の準備
+	var recorder *executil.Recorder
+	if recordDir != "" {
+		recorder = executil.NewRecorder()
+		opt.Middlewares = append(opt.Middlewares, recorder.Middleware())
+	}
+	if replayDir != "" {
+		replayer, err := executil.LoadReplayer(replayDir)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(ExitUsage)
+		}
+		// 記録時のホスト/プラットフォームとして評価する
+		opt.Hostname = replayer.Manifest.Hostname
+		opt.Platform = replayer.Manifest.Platform
+		opt.Root = replayer.Manifest.Root
+		opt.Middlewares = append(opt.Middlewares, replayer.Middleware())
+	}
+
+	// --template: 監査の前に読み込んで構文エラーを報告する
+	var tmpl *template.Template
+	if cfg.Template != "" {
+		tmpl, err = output.ParseTemplate(cfg.Template)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(ExitUsage)
+		}
+	}
+
+	// --require-root: 権限不足で一部だけ評価されるのを防ぐ
+	if cfg.RequireRoot && !opt.Root {
+		if ids := runner.RootRequired(opt); len(ids) > 0 {
+			fmt.Fprintf(os.Stderr, "--require-root: %s need root; re-run with sudo\n", strings.Join(ids, ", "))
+			os.Exit(ExitUsage)
+		}
+	}
+
+	// 監査証跡（実行したコマンドを1件ごとに追記する）
+	// 使い方・権限の確認をすべて通ってから作成し、拒否した実行でファイルを残さない
+	var trace *os.File
+	if traceFile != "" {
+		trace, err = os.Create(traceFile)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(ExitUsage)
+		}
+		opt.Trace = trace
+	}
+
+	// レポートの出力先（--output 指定時はファイル、監査証跡と同じく確認を通ってから作成する）
+	out := os.Stdout
+	if outputFile != "" {
+		out, err = os.Create(outputFile)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			// 作成済みの監査証跡も残さない
+			if trace != nil {
+				_ = trace.Close()
+				_ = os.Remove(traceFile)
+			}
+			os.Exit(ExitUsage)
+		}
+	}
+
+	// 監査の実行（シグナル・--deadline で中断しても部分的なレポートを出力する）
+	ctx, stop := signalContext(context.Background())
+	defer stop()
+	if cfg.Deadline > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, cfg.Deadline, fmt.Errorf("audit deadline %s exceeded", cfg.Deadline))
+		defer cancel()
+	}
+	// ndjson は監査中に進行イベントを逐次出力する
+	var stream *output.NDJSONWriter
+	if cfg.Format == "ndjson" && tmpl == nil {
+		stream = output.NewNDJSONWriter(out)
+		opt.OnEvent = stream.Event
+	}
+	rep := runner.Run(ctx, version, opt)
+
+	if trace != nil {
+		if err := trace.Close(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(ExitInternal)
+		}
+	}
+
+	if recorder != nil {
+		m := executil.Manifest{
+			Version:    version,
+			RecordedAt: time.Now().UTC(),
+			Hostname:   rep.Host.Hostname,
+			Platform:   runtime.GOOS,
+			Root:       opt.Root,
+		}
+		if err := recorder.Save(recordDir, m); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(ExitInternal)
+		}
+	}
+
+	// 出力モード（並び順はすべての出力形式・テンプレートで共通、ndjson は出力済み）
+	if stream != nil {
+		err = stream.Err()
 	} else {
-		err = output.WriteTable(os.Stdout, rep)
+		var sorted types.Report
+		sorted, err = output.Sort(rep, cfg.Sort)
+		if err == nil && tmpl != nil {
+			err = output.WriteTemplate(out, tmpl, sorted)
+		} else if err == nil {
+			err = output.Write(out, cfg.Format, sorted)
+		}
+	}
+	if out != os.Stdout {
+		if cerr := out.Close(); err == nil {
+			err = cerr
+		}
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(ExitInternal)
 	}
+
+	// 結果に応じた終了コード
+	code, reason := evaluateExit(rep, cfg.FailOn, cfg.MinScore)
+	if reason != "" {
+		fmt.Fprintln(os.Stderr, reason)
+	}
+	os.Exit(code)
+}
+
+// SIGINT / SIGTERM を受けたら ctx を中断する（2回目のシグナルは既定の動作で終了）
+func signalContext(parent context.Context) (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(parent)
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case sig := <-ch:
+			signal.Stop(ch)
+			fmt.Fprintf(os.Stderr, "received %s, cancelling running checks\n", sig)
+			cancel(fmt.Errorf("interrupted by %s", sig))
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, func() {
+		signal.Stop(ch)
+		cancel(nil)
+	}
+}
+
+// config サブコマンド（show のみ）
+func runConfig(args []string) {
+	if len(args) < 1 || args[0] != "show" {
+		usage()
+		os.Exit(ExitUsage)
+	}
+
+	fs := flag.NewFlagSet("config show", flag.ExitOnError)
+	flags := registerAuditFlags(fs)
+	_ = fs.Parse(args[1:])
+
+	cfg, err := flags.effectiveConfig(fs)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
-		os.Exit(2)
+		os.Exit(ExitUsage)
+	}
+	if err := cfg.Write(os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(ExitInternal)
+	}
+}
+
+// profiles サブコマンド（list / show <name>）
+func runProfiles(args []string) {
+	if len(args) < 1 {
+		usage()
+		os.Exit(ExitUsage)
+	}
+
+	switch args[0] {
+	case "list":
+		all, err := profile.All()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(ExitUsage)
+		}
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "NAME\tCHECKS\tSOURCE\tTITLE")
+		for _, p := range all {
+			fmt.Fprintf(tw, "%s\t%d\t%s\t%s\n", p.Name, len(p.Checks), p.Source, p.Title)
+		}
+		_ = tw.Flush()
+	case "show":
+		if len(args) < 2 {
+			fmt.Fprintln(os.Stderr, "usage: macinsight profiles show <name|file>")
+			os.Exit(ExitUsage)
+		}
+		p, err := profile.Get(args[1])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(ExitUsage)
+		}
+		if err := p.Write(os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(ExitInternal)
+		}
+	default:
+		usage()
+		os.Exit(ExitUsage)
 	}
 }
 
+// ルールファイルとプラグインを読み込んでチェックとして登録する
+func registerExtensions(rulesDirs, pluginDirs []string, pluginsPath bool) error {
+	for _, dir := range rulesDirs {
+		rs, err := rules.LoadDir(dir)
+		if err != nil {
+			return err
+		}
+		for _, r := range rs {
+			if err := checks.Register(r.Check()); err != nil {
+				return fmt.Errorf("rule %s: %w", r.ID, err)
+			}
+		}
+	}
+
+	paths, err := plugin.Discover(pluginDirs, pluginsPath)
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		p, err := plugin.Load(context.Background(), path)
+		if err != nil {
+			return err
+		}
+		if err := checks.Register(p.Check()); err != nil {
+			return fmt.Errorf("plugin %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// list-checks / schema 用：フラグで指定された拡張を登録する
+func (e *extensionFlags) register() error {
+	return registerExtensions(splitList(e.rules), splitList(e.plugins), e.pluginsPath)
+}
+
+// 登録済みチェックの一覧を表示
+func runListChecks(args []string) {
+	fs := flag.NewFlagSet("list-checks", flag.ExitOnError)
+	ext := registerExtensionFlags(fs)
+	_ = fs.Parse(args)
+
+	if err := ext.register(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(ExitUsage)
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tWEIGHT\tSEVERITY\tCATEGORY\tPLATFORMS\tROOT\tTITLE")
+	for _, c := range checks.All() {
+		root := "-"
+		if c.RequiresRoot() {
+			root = "yes"
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t%s\t%s\t%s\n", c.ID(), c.Weight(), c.Severity(), c.Category(), strings.Join(c.Platforms(), ","), root, c.Title())
+	}
+	_ = tw.Flush()
+}
+
+// 登録済みチェックが実行しうる外部コマンドの一覧を表示
+func runListCommands(args []string) {
+	fs := flag.NewFlagSet("list-commands", flag.ExitOnError)
+	ext := registerExtensionFlags(fs)
+	_ = fs.Parse(args)
+
+	if err := ext.register(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(ExitUsage)
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "CHECK\tCOMMAND")
+	for _, argv := range runner.HostCommands {
+		fmt.Fprintf(tw, "%s\t%s\n", "(host)", strings.Join(argv, " "))
+	}
+	for _, c := range checks.All() {
+		for _, argv := range c.Commands() {
+			fmt.Fprintf(tw, "%s\t%s\n", c.ID(), strings.Join(argv, " "))
+		}
+	}
+	_ = tw.Flush()
+}
+
+// カンマ区切りを順序を保ってスライスにする
+func splitList(csv string) []string {
+	var list []string
+	for _, v := range strings.Split(csv, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
 func toSet(csv string) map[string]struct{} {
 	m := map[string]struct{}{}
 	if strings.TrimSpace(csv) == "" {
@@ -101,13 +605,28 @@ func toSet(csv string) map[string]struct{} {
 	return m
 }
 
+func setKeys(m map[string]struct{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 func runSchema(args []string) {
 	// フラグ定義
 	fs := flag.NewFlagSet("schema", flag.ExitOnError)
 	var outputFile string
 	fs.StringVar(&outputFile, "output", "", "output file path (default: stdout)")
+	ext := registerExtensionFlags(fs)
 	_ = fs.Parse(args)
 
+	if err := ext.register(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(ExitUsage)
+	}
+
 	// スキーマ生成
 	generator := &schema.JSONSchemaGenerator{}
 
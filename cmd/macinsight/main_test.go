@@ -3,6 +3,8 @@ package main
 import (
 	"strings"
 	"testing"
+
+	"github.com/samuraidays/macinsight/pkg/types"
 )
 
 func TestToSet(t *testing.T) {
@@ -36,3 +38,41 @@ func TestUsageTextContainsCommands(t *testing.T) {
 		t.Fatalf("usage text missing keywords")
 	}
 }
+
+func TestEvaluateExit(t *testing.T) {
+	rep := types.Report{
+		Score: 70,
+		Checks: []types.CheckResult{
+			{ID: "sip", Status: "pass"},
+			{ID: "osupdate", Status: "warn"},
+			{ID: "firewall", Status: "not_applicable"},
+		},
+	}
+
+	cases := []struct {
+		name     string
+		checks   []types.CheckResult
+		failOn   string
+		minScore int
+		want     int
+	}{
+		{"no gating", rep.Checks, "", 0, ExitOK},
+		{"fail-on fail ignores warn", rep.Checks, "fail", 0, ExitOK},
+		{"fail-on warn", rep.Checks, "warn", 0, ExitPolicy},
+		{"min-score met", rep.Checks, "", 70, ExitOK},
+		{"min-score missed", rep.Checks, "", 80, ExitPolicy},
+		{"unknown is an error", append(rep.Checks, types.CheckResult{ID: "filevault", Status: "unknown"}), "fail", 0, ExitCheckError},
+		{"cancelled is an error", append(rep.Checks, types.CheckResult{ID: "filevault", Status: "cancelled"}), "", 0, ExitCheckError},
+		{"skipped is an error", append(rep.Checks, types.CheckResult{ID: "filevault", Status: "skipped"}), "fail", 0, ExitCheckError},
+		{"fail-on unknown", append(rep.Checks, types.CheckResult{ID: "filevault", Status: "unknown"}), "unknown", 0, ExitPolicy},
+		{"fail-on unknown includes skipped", append(rep.Checks, types.CheckResult{ID: "filevault", Status: "skipped"}), "unknown", 0, ExitPolicy},
+	}
+	for _, c := range cases {
+		r := rep
+		r.Checks = c.checks
+		got, reason := evaluateExit(r, c.failOn, c.minScore)
+		if got != c.want {
+			t.Errorf("%s: exit=%d (%s), want %d", c.name, got, reason, c.want)
+		}
+	}
+}
@@ -2,7 +2,11 @@ module github.com/samuraidays/macinsight
 
 go 1.23
 
-require github.com/jedib0t/go-pretty/v6 v6.6.8
+require (
+	github.com/jedib0t/go-pretty/v6 v6.6.8
+	github.com/santhosh-tekuri/jsonschema/v5 v5.3.1
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require (
 	github.com/mattn/go-runewidth v0.0.16 // indirect
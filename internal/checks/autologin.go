@@ -5,29 +5,58 @@ import (
 	"strings"
 	"time"
 
+	"github.com/samuraidays/macinsight/internal/executil"
 	"github.com/samuraidays/macinsight/pkg/types"
 )
 
+var autologinDef = Definition{
+	ID:          "autologin",
+	Title:       "Auto-login disabled",
+	Weight:      10,
+	Category:    CategoryAuthentication,
+	Severity:    SeverityHigh,
+	Description: "loginwindow の autoLoginUser が未設定か確認",
+	Platforms:   []string{"darwin"},
+	Controls: []types.Control{
+		{Framework: FrameworkNIST, ID: "IA-2"},
+		{Framework: FrameworkCIS, ID: "4.1"},
+		{Framework: FrameworkISO, ID: "A.8.5"},
+	},
+	Commands: [][]string{
+		{"/usr/bin/defaults", "read", "/Library/Preferences/com.apple.loginwindow", "autoLoginUser"},
+	},
+}
+
+func init() {
+	MustRegister(New(autologinDef, AutoLogin))
+}
+
 // AutoLogin（自動ログイン）状態
 // 重みは 10 点
 func AutoLogin(ctx context.Context) types.CheckResult {
-	const weight = 10
-
 	// 自動ログインの設定を確認
 	res := runCommand(ctx, 3*time.Second, "/usr/bin/defaults", "read", "/Library/Preferences/com.apple.loginwindow", "autoLoginUser")
 	ev := map[string]string{"autoLoginUser": strings.TrimSpace(res.Stdout)}
 
 	cr := types.CheckResult{
-		ID:       "autologin",
-		Title:    "Auto-login disabled",
+		ID:       autologinDef.ID,
+		Title:    autologinDef.Title,
 		Evidence: ev,
 	}
 
+	// defaults が起動できない・権限が無い・タイムアウトした場合は判定できない
+	switch executil.KindOf(res.Err) {
+	case executil.KindNotFound, executil.KindPermissionDenied, executil.KindTimeout, executil.KindKilled:
+		cr.Status = "unknown"
+		cr.Error = ErrorOf(res.Err)
+		cr.Recommendation = recommendFor(res.Err, "")
+		return cr
+	}
+
 	if res.Err != nil {
 		// エラーの場合、設定ファイルが存在しないか、キーが存在しない可能性
 		// この場合は自動ログインが無効とみなす
 		cr.Status = "pass"
-		cr.Score = weight
 		cr.Evidence["note"] = "Auto-login setting not found (disabled by default)"
 		return cr
 	}
@@ -36,10 +65,8 @@ func AutoLogin(ctx context.Context) types.CheckResult {
 	output := strings.TrimSpace(res.Stdout)
 	if output == "" || output == "()" || output == "0" {
 		cr.Status = "pass"
-		cr.Score = weight
 	} else {
 		cr.Status = "fail"
-		cr.Score = 0
 		cr.Recommendation = "自動ログインを無効にしてください: システム設定 > ユーザとグループ > ログインオプション"
 	}
 
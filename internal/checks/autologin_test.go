@@ -34,3 +34,16 @@ func TestAutoLogin_FailWhenUserSet(t *testing.T) {
 		t.Fatalf("AutoLogin should fail when user is set, got %s", cr.Status)
 	}
 }
+
+func TestAutoLogin_UnknownWhenDefaultsMissing(t *testing.T) {
+	orig := runCommand
+	runCommand = func(ctx context.Context, timeout time.Duration, name string, args ...string) executil.Result {
+		return executil.Result{ExitCode: -1, Err: &executil.Error{Kind: executil.KindNotFound, Message: "/usr/bin/defaults not found"}}
+	}
+	t.Cleanup(func() { runCommand = orig })
+
+	cr := AutoLogin(context.Background())
+	if cr.Status != "unknown" || cr.Error == nil || cr.Error.Kind != "not_found" {
+		t.Fatalf("AutoLogin should be unknown when defaults is missing, got status=%s error=%+v", cr.Status, cr.Error)
+	}
+}
@@ -0,0 +1,203 @@
+package checks
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/samuraidays/macinsight/pkg/types"
+)
+
+// チェックのカテゴリ
+const (
+	CategoryEncryption      = "encryption"
+	CategorySystemIntegrity = "system_integrity"
+	CategoryNetwork         = "network"
+	CategoryAuthentication  = "authentication"
+	CategoryPatching        = "patching"
+)
+
+// 管理策のフレームワーク名
+const (
+	FrameworkNIST = "NIST SP 800-53 Rev. 5"
+	FrameworkCIS  = "CIS Controls v8"
+	FrameworkISO  = "ISO/IEC 27001:2022"
+)
+
+// 重要度（重い順）
+const (
+	SeverityCritical = "critical"
+	SeverityHigh     = "high"
+	SeverityMedium   = "medium"
+	SeverityLow      = "low"
+	SeverityInfo     = "info"
+)
+
+// Severities は重要度を重い順に並べたもの
+var Severities = []string{SeverityCritical, SeverityHigh, SeverityMedium, SeverityLow, SeverityInfo}
+
+// IsSeverity は重要度名が有効か返す
+func IsSeverity(s string) bool {
+	for _, v := range Severities {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Check は監査チェック1件を表すインターフェース
+type Check interface {
+	ID() string                // 例: "gatekeeper"
+	Title() string             // 例: "Gatekeeper enabled"
+	Weight() int               // スコア配点
+	Category() string          // 例: "system_integrity"
+	Severity() string          // 既定の重要度（プロファイルで上書き可）
+	Description() string       // チェック内容の説明
+	Platforms() []string       // 対象プラットフォーム（runtime.GOOS の値）
+	Controls() []types.Control // 対応するコンプライアンス管理策
+	RequiresRoot() bool        // root 権限が無いと正しく判定できないか
+	Commands() [][]string      // 実行する可能性のある外部コマンド（argv の一覧）
+	Run(ctx context.Context) types.CheckResult
+}
+
+// Definition はチェックのメタデータ
+type Definition struct {
+	ID           string
+	Title        string
+	Weight       int
+	Category     string
+	Severity     string // 省略時 medium
+	Description  string
+	Platforms    []string
+	Controls     []types.Control
+	RequiresRoot bool
+	Commands     [][]string // 例: {{"/usr/bin/fdesetup", "status"}}
+}
+
+// New はメタデータと実行関数から Check を作る
+func New(def Definition, run func(context.Context) types.CheckResult) Check {
+	return &check{def: def, run: run}
+}
+
+type check struct {
+	def Definition
+	run func(context.Context) types.CheckResult
+}
+
+func (c *check) ID() string          { return c.def.ID }
+func (c *check) Title() string       { return c.def.Title }
+func (c *check) Weight() int         { return c.def.Weight }
+func (c *check) Category() string    { return c.def.Category }
+func (c *check) Description() string { return c.def.Description }
+func (c *check) Platforms() []string { return c.def.Platforms }
+
+func (c *check) Controls() []types.Control { return c.def.Controls }
+
+func (c *check) RequiresRoot() bool { return c.def.RequiresRoot }
+
+func (c *check) Commands() [][]string { return c.def.Commands }
+
+func (c *check) Severity() string {
+	if c.def.Severity == "" {
+		return SeverityMedium
+	}
+	return c.def.Severity
+}
+
+func (c *check) Run(ctx context.Context) types.CheckResult { return c.run(ctx) }
+
+// チェックのレジストリ（登録順を保持）
+var (
+	registryMu sync.RWMutex
+	registry   []Check
+	registryID = map[string]Check{}
+)
+
+// Register はチェックをレジストリに追加する（ID重複はエラー）
+func Register(c Check) error {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if c.ID() == "" {
+		return fmt.Errorf("check ID is required")
+	}
+	if !IsSeverity(c.Severity()) {
+		return fmt.Errorf("check %q has invalid severity %q (want one of %v)", c.ID(), c.Severity(), Severities)
+	}
+	if _, dup := registryID[c.ID()]; dup {
+		return fmt.Errorf("check %q is already registered", c.ID())
+	}
+	registry = append(registry, c)
+	registryID[c.ID()] = c
+	return nil
+}
+
+// MustRegister は Register の init 用版（失敗時は panic）
+func MustRegister(c Check) {
+	if err := Register(c); err != nil {
+		panic(err)
+	}
+}
+
+// All は登録済みチェックを登録順で返す
+func All() []Check {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return append([]Check(nil), registry...)
+}
+
+// Lookup は ID からチェックを引く
+func Lookup(id string) (Check, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	c, ok := registryID[id]
+	return c, ok
+}
+
+// IDs は登録済みチェックIDを登録順で返す
+func IDs() []string {
+	all := All()
+	ids := make([]string, 0, len(all))
+	for _, c := range all {
+		ids = append(ids, c.ID())
+	}
+	return ids
+}
+
+// IDPattern はルール・プラグインのチェックIDに使える形式（出力や設定ファイルのキーとして扱えるもの）
+var IDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]*$`)
+
+type paramsKey struct{}
+
+// WithParams はチェック別パラメータを context に載せる
+func WithParams(ctx context.Context, params map[string]string) context.Context {
+	return context.WithValue(ctx, paramsKey{}, params)
+}
+
+// Params は context に載っているチェック別パラメータを返す
+func Params(ctx context.Context) map[string]string {
+	params, _ := ctx.Value(paramsKey{}).(map[string]string)
+	return params
+}
+
+// Param は context からチェック別パラメータを取り出す
+func Param(ctx context.Context, key string) (string, bool) {
+	v, ok := Params(ctx)[key]
+	return v, ok
+}
+
+type timeoutKey struct{}
+
+// WithTimeout はチェック別タイムアウトを context に載せる（打ち切り自体は呼び出し側の context で行う）
+func WithTimeout(ctx context.Context, d time.Duration) context.Context {
+	return context.WithValue(ctx, timeoutKey{}, d)
+}
+
+// Timeout は context に載っているチェック別タイムアウトを返す
+func Timeout(ctx context.Context) (time.Duration, bool) {
+	d, ok := ctx.Value(timeoutKey{}).(time.Duration)
+	return d, ok && d > 0
+}
@@ -0,0 +1,58 @@
+package checks
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/samuraidays/macinsight/internal/executil"
+	"github.com/samuraidays/macinsight/pkg/types"
+)
+
+func TestRegistry_BuiltinsRegistered(t *testing.T) {
+	for _, id := range []string{"sip", "gatekeeper", "filevault", "firewall", "autologin", "osupdate"} {
+		c, ok := Lookup(id)
+		if !ok {
+			t.Fatalf("builtin check %q not registered", id)
+		}
+		if c.Title() == "" || c.Weight() <= 0 || c.Category() == "" {
+			t.Fatalf("check %q has incomplete metadata: title=%q weight=%d category=%q", id, c.Title(), c.Weight(), c.Category())
+		}
+	}
+}
+
+func TestRegister_RejectsDuplicateID(t *testing.T) {
+	dup := New(Definition{ID: "sip", Title: "dup"}, func(context.Context) types.CheckResult { return types.CheckResult{} })
+	if err := Register(dup); err == nil {
+		t.Fatal("duplicate registration should fail")
+	}
+}
+
+func TestBuiltins_RunOnlyDeclaredCommands(t *testing.T) {
+	var ran []string
+	orig := runCommand
+	runCommand = func(ctx context.Context, timeout time.Duration, name string, args ...string) executil.Result {
+		ran = append(ran, strings.Join(append([]string{name}, args...), " "))
+		return executil.Result{}
+	}
+	t.Cleanup(func() { runCommand = orig })
+
+	for _, id := range []string{"sip", "gatekeeper", "filevault", "firewall", "autologin", "osupdate"} {
+		c, _ := Lookup(id)
+		declared := map[string]bool{}
+		for _, argv := range c.Commands() {
+			declared[strings.Join(argv, " ")] = true
+		}
+		ran = nil
+		c.Run(context.Background())
+		if len(ran) == 0 {
+			t.Fatalf("check %q ran no commands", id)
+		}
+		for _, cmd := range ran {
+			if !declared[cmd] {
+				t.Fatalf("check %q ran %q which is not in Commands() %v", id, cmd, c.Commands())
+			}
+		}
+	}
+}
@@ -8,33 +8,53 @@ import (
 	"github.com/samuraidays/macinsight/pkg/types"
 )
 
+var filevaultDef = Definition{
+	ID:          "filevault",
+	Title:       "FileVault enabled",
+	Weight:      20,
+	Category:    CategoryEncryption,
+	Severity:    SeverityCritical,
+	Description: "fdesetup status でディスク暗号化が有効か確認",
+	Platforms:   []string{"darwin"},
+	Controls: []types.Control{
+		{Framework: FrameworkNIST, ID: "SC-28"},
+		{Framework: FrameworkNIST, ID: "SC-28(1)"},
+		{Framework: FrameworkCIS, ID: "3.6"},
+		{Framework: FrameworkISO, ID: "A.8.24"},
+		{Framework: FrameworkISO, ID: "A.8.1"},
+	},
+	Commands: [][]string{
+		{"/usr/bin/fdesetup", "status"},
+	},
+}
+
+func init() {
+	MustRegister(New(filevaultDef, FileVault))
+}
+
 // FileVault（フルディスク暗号化）の有効/無効
 // 重みは 20 点（重要度高）
 func FileVault(ctx context.Context) types.CheckResult {
-	const weight = 20
-
 	res := runCommand(ctx, 3*time.Second, "/usr/bin/fdesetup", "status")
 	ev := map[string]string{"fdesetup": strings.TrimSpace(res.Stdout)}
 
 	cr := types.CheckResult{
-		ID:       "filevault",
-		Title:    "FileVault enabled",
+		ID:       filevaultDef.ID,
+		Title:    filevaultDef.Title,
 		Evidence: ev,
 	}
 
 	if res.Err != nil {
 		cr.Status = "unknown"
-		cr.Score = weight / 2
-		cr.Recommendation = "管理者権限が必要な場合があります"
+		cr.Error = ErrorOf(res.Err)
+		cr.Recommendation = recommendFor(res.Err, "管理者権限が必要な場合があります")
 		return cr
 	}
 
 	if strings.Contains(res.Stdout, "FileVault is On") {
 		cr.Status = "pass"
-		cr.Score = weight
 	} else {
 		cr.Status = "fail"
-		cr.Score = 0
 		cr.Recommendation = "FileVault 有効化を検討（システム設定 > プライバシーとセキュリティ > FileVault）"
 	}
 
@@ -3,6 +3,7 @@ package checks
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 	"time"
 
@@ -17,7 +18,8 @@ func TestFileVault_Pass(t *testing.T) {
 	t.Cleanup(func() { runCommand = orig })
 
 	cr := FileVault(context.Background())
-	if cr.Status != "pass" || cr.Score == 0 {
+	// 点数は scoring で付けるため、チェック自身は Score を設定しない
+	if cr.Status != "pass" || cr.Score != 0 {
 		t.Fatalf("FileVault pass expected, got status=%s score=%d", cr.Status, cr.Score)
 	}
 }
@@ -34,3 +36,19 @@ func TestFileVault_UnknownOnError(t *testing.T) {
 		t.Fatalf("FileVault unknown expected on error, got %s", cr.Status)
 	}
 }
+
+func TestFileVault_ReportsErrorKind(t *testing.T) {
+	orig := runCommand
+	runCommand = func(ctx context.Context, timeout time.Duration, name string, args ...string) executil.Result {
+		return executil.Result{ExitCode: 1, Err: &executil.Error{Kind: executil.KindPermissionDenied, Message: "fdesetup requires root"}}
+	}
+	t.Cleanup(func() { runCommand = orig })
+
+	cr := FileVault(context.Background())
+	if cr.Status != "unknown" || cr.Error == nil || cr.Error.Kind != "permission_denied" || cr.Error.Message != "fdesetup requires root" {
+		t.Fatalf("FileVault should carry the error kind, got status=%s error=%+v", cr.Status, cr.Error)
+	}
+	if !strings.Contains(cr.Recommendation, "sudo") {
+		t.Fatalf("recommendation should mention sudo, got %q", cr.Recommendation)
+	}
+}
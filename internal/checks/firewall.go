@@ -8,24 +8,45 @@ import (
 	"github.com/samuraidays/macinsight/pkg/types"
 )
 
+var firewallDef = Definition{
+	ID:          "firewall",
+	Title:       "Firewall enabled",
+	Weight:      10,
+	Category:    CategoryNetwork,
+	Severity:    SeverityMedium,
+	Description: "socketfilterfw でアプリケーション・ファイアウォールが有効か確認",
+	Platforms:   []string{"darwin"},
+	Controls: []types.Control{
+		{Framework: FrameworkNIST, ID: "SC-7"},
+		{Framework: FrameworkNIST, ID: "SC-7(12)"},
+		{Framework: FrameworkCIS, ID: "4.5"},
+		{Framework: FrameworkISO, ID: "A.8.20"},
+	},
+	Commands: [][]string{
+		{"/usr/libexec/ApplicationFirewall/socketfilterfw", "--getglobalstate"},
+	},
+}
+
+func init() {
+	MustRegister(New(firewallDef, Firewall))
+}
+
 // macOSアプリケーションファイアウォールの有効/無効
 // 重みは 10 点
 func Firewall(ctx context.Context) types.CheckResult {
-	const weight = 10
-
 	res := runCommand(ctx, 3*time.Second, "/usr/libexec/ApplicationFirewall/socketfilterfw", "--getglobalstate")
 	ev := map[string]string{"socketfilterfw": strings.TrimSpace(res.Stdout)}
 
 	cr := types.CheckResult{
-		ID:       "firewall",
-		Title:    "Firewall enabled",
+		ID:       firewallDef.ID,
+		Title:    firewallDef.Title,
 		Evidence: ev,
 	}
 
 	if res.Err != nil {
 		cr.Status = "unknown"
-		cr.Score = weight / 2
-		cr.Recommendation = "管理者権限が必要な場合があります"
+		cr.Error = ErrorOf(res.Err)
+		cr.Recommendation = recommendFor(res.Err, "管理者権限が必要な場合があります")
 		return cr
 	}
 
@@ -33,10 +54,8 @@ func Firewall(ctx context.Context) types.CheckResult {
 	out := strings.ToLower(res.Stdout)
 	if strings.Contains(out, "state = 1") || strings.Contains(out, "enabled") {
 		cr.Status = "pass"
-		cr.Score = weight
 	} else {
 		cr.Status = "fail"
-		cr.Score = 0
 		cr.Recommendation = "システム設定 > ネットワーク > ファイアウォール を有効化"
 	}
 
@@ -8,14 +8,37 @@ import (
 	"github.com/samuraidays/macinsight/pkg/types"
 )
 
+var osupdateDef = Definition{
+	ID:          "osupdate",
+	Title:       "OS updates current",
+	Weight:      20,
+	Category:    CategoryPatching,
+	Severity:    SeverityHigh,
+	Description: "softwareupdate -l --no-scan で未適用の更新がないか確認",
+	Platforms:   []string{"darwin"},
+	Controls: []types.Control{
+		{Framework: FrameworkNIST, ID: "SI-2"},
+		{Framework: FrameworkCIS, ID: "7.3"},
+		{Framework: FrameworkISO, ID: "A.8.8"},
+	},
+	Commands: [][]string{
+		{"/usr/bin/sw_vers"},
+		{"/usr/sbin/softwareupdate", "-l", "--no-scan"},
+	},
+}
+
+func init() {
+	MustRegister(New(osupdateDef, OSUpdate))
+}
+
 // OSUpdate（OS更新状況）チェック
 // 重みは 20 点
+// パラメータ strict=true で一般更新のみの場合も fail とする
 func OSUpdate(ctx context.Context) types.CheckResult {
-	const weight = 20
-
 	// 現在のOSバージョン（参考情報としてevidenceに載せる）
-	swVersRes := runCommand(ctx, 3*time.Second, "/usr/bin/sw_vers", "-productVersion")
-	currentVersion := strings.TrimSpace(swVersRes.Stdout)
+	// ホスト情報と同じ引数無しの sw_vers を使い、実行結果のキャッシュを共有する
+	swVersRes := runCommand(ctx, 3*time.Second, "/usr/bin/sw_vers")
+	currentVersion := swVersValue(swVersRes.Stdout, "ProductVersion")
 	if currentVersion == "" {
 		currentVersion = "unknown"
 	}
@@ -28,16 +51,16 @@ func OSUpdate(ctx context.Context) types.CheckResult {
 	}
 
 	cr := types.CheckResult{
-		ID:       "osupdate",
-		Title:    "OS updates current",
+		ID:       osupdateDef.ID,
+		Title:    osupdateDef.Title,
 		Evidence: ev,
 	}
 
-	// softwareupdate -l --no-scan が失敗した場合の代替手段
+	// softwareupdate -l --no-scan が失敗した場合は判定できない（採点は unknown ポリシーに従う）
 	if updateRes.Err != nil {
-		cr.Status = "warn"
-		cr.Score = weight / 2
-		cr.Recommendation = "OS更新状況の確認に失敗しました。システム設定 > ソフトウェアアップデート から手動で確認してください"
+		cr.Status = "unknown"
+		cr.Error = ErrorOf(updateRes.Err)
+		cr.Recommendation = recommendFor(updateRes.Err, "OS更新状況の確認に失敗しました。システム設定 > ソフトウェアアップデート から手動で確認してください")
 		return cr
 	}
 
@@ -55,7 +78,6 @@ func OSUpdate(ctx context.Context) types.CheckResult {
 
 	if containsAny(updateOutput, noUpdateMarkers) || strings.TrimSpace(updateRes.Stdout) == "" {
 		cr.Status = "pass"
-		cr.Score = weight
 		cr.Recommendation = "OSは最新の状態です。定期的な更新を継続してください"
 		return cr
 	}
@@ -66,7 +88,6 @@ func OSUpdate(ctx context.Context) types.CheckResult {
 	if !hasUpdateItems {
 		// 更新項目がない場合は更新なしとして扱う
 		cr.Status = "pass"
-		cr.Score = weight
 		cr.Recommendation = "OSは最新の状態です。定期的な更新を継続してください"
 		return cr
 	}
@@ -75,13 +96,16 @@ func OSUpdate(ctx context.Context) types.CheckResult {
 	securityUpdates := extractSecurityUpdates(updateRes.Stdout)
 	if len(securityUpdates) > 0 {
 		cr.Status = "fail"
-		cr.Score = 0
 		cr.Recommendation = "セキュリティ更新が利用可能です。システム設定 > ソフトウェアアップデート から更新してください"
 		ev["updates"] = strings.Join(securityUpdates, "; ")
+	} else if strict, _ := Param(ctx, "strict"); strict == "true" {
+		// strict パラメータ指定時は一般更新のみでも fail
+		cr.Status = "fail"
+		cr.Recommendation = "OS更新が利用可能です。システム設定 > ソフトウェアアップデート から更新してください"
+		ev["updates"] = "一般更新が利用可能"
 	} else {
 		// セキュリティ以外の更新のみの場合
 		cr.Status = "warn"
-		cr.Score = weight / 2
 		cr.Recommendation = "OS更新が利用可能です。セキュリティ更新を優先して適用してください"
 		ev["updates"] = "一般更新が利用可能"
 	}
@@ -135,3 +159,14 @@ func containsAny(s string, subs []string) bool {
 	}
 	return false
 }
+
+// sw_vers の "Key:\tvalue" 形式の出力から値を取り出す
+func swVersValue(out, key string) string {
+	for _, l := range strings.Split(out, "\n") {
+		k, v, ok := strings.Cut(l, ":")
+		if ok && strings.TrimSpace(k) == key {
+			return strings.TrimSpace(v)
+		}
+	}
+	return ""
+}
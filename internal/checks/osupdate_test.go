@@ -0,0 +1,29 @@
+package checks
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/samuraidays/macinsight/internal/executil"
+)
+
+func TestOSUpdate_UnknownWhenSoftwareupdateFails(t *testing.T) {
+	orig := runCommand
+	runCommand = func(ctx context.Context, timeout time.Duration, name string, args ...string) executil.Result {
+		if name == "/usr/bin/sw_vers" {
+			return executil.Result{Stdout: "ProductName:\tmacOS\nProductVersion:\t15.6.1\nBuildVersion:\t24G90\n"}
+		}
+		return executil.Result{Err: &executil.Error{Kind: executil.KindTimeout, Message: "softwareupdate timed out after 8s"}}
+	}
+	t.Cleanup(func() { runCommand = orig })
+
+	// 判定できなかった場合は unknown とし、採点は unknown ポリシーに任せる
+	cr := OSUpdate(context.Background())
+	if cr.Status != "unknown" || cr.Score != 0 {
+		t.Fatalf("OSUpdate unknown expected on error, got status=%s score=%d", cr.Status, cr.Score)
+	}
+	if cr.Error == nil || cr.Error.Kind != "timeout" || cr.Evidence["version"] != "15.6.1" {
+		t.Fatalf("unexpected result: %+v", cr)
+	}
+}
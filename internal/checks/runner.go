@@ -5,9 +5,31 @@ import (
 	"time"
 
 	"github.com/samuraidays/macinsight/internal/executil"
+	"github.com/samuraidays/macinsight/pkg/types"
 )
 
 // runCommand is an indirection over executil.Run to allow tests to mock command execution.
 var runCommand = func(ctx context.Context, timeout time.Duration, name string, args ...string) executil.Result {
 	return executil.Run(ctx, timeout, name, args...)
 }
+
+// ErrorOf はコマンドの実行エラーを結果に載せる分類付きエラーに変換する（nil なら nil）
+func ErrorOf(err error) *types.CheckError {
+	if err == nil {
+		return nil
+	}
+	return &types.CheckError{Kind: string(executil.KindOf(err)), Message: err.Error()}
+}
+
+// recommendFor はエラーの分類に応じた推奨を返す（該当しなければ fallback）
+func recommendFor(err error, fallback string) string {
+	switch executil.KindOf(err) {
+	case executil.KindPermissionDenied:
+		return "root 権限が必要です。sudo で再実行してください"
+	case executil.KindNotFound:
+		return "コマンドが見つかりません。OSバージョン差やパスを確認"
+	case executil.KindTimeout:
+		return "--timeout（または設定の timeouts）を延ばして再実行"
+	}
+	return fallback
+}
@@ -8,34 +8,52 @@ import (
 	"github.com/samuraidays/macinsight/pkg/types"
 )
 
+var sipDef = Definition{
+	ID:          "sip",
+	Title:       "System Integrity Protection enabled",
+	Weight:      20,
+	Category:    CategorySystemIntegrity,
+	Severity:    SeverityCritical,
+	Description: "csrutil status で System Integrity Protection が有効か確認",
+	Platforms:   []string{"darwin"},
+	Controls: []types.Control{
+		{Framework: FrameworkNIST, ID: "SI-7"},
+		{Framework: FrameworkCIS, ID: "4.1"},
+		{Framework: FrameworkISO, ID: "A.8.9"},
+	},
+	Commands: [][]string{
+		{"/usr/bin/csrutil", "status"},
+	},
+}
+
+func init() {
+	MustRegister(New(sipDef, SIP))
+}
+
 // SIP（System Integrity Protection）状態
 // 重みは 20 点
 func SIP(ctx context.Context) types.CheckResult {
-	const weight = 20
-
 	res := runCommand(ctx, 3*time.Second, "/usr/bin/csrutil", "status")
 	ev := map[string]string{"csrutil": strings.TrimSpace(res.Stdout)}
 
 	cr := types.CheckResult{
-		ID:       "sip",
-		Title:    "System Integrity Protection enabled",
+		ID:       sipDef.ID,
+		Title:    sipDef.Title,
 		Evidence: ev,
 	}
 
 	if res.Err != nil {
 		cr.Status = "unknown"
-		cr.Score = weight / 2
-		cr.Recommendation = "csrutil の場所/実行可否やOSバージョン差を確認"
+		cr.Error = ErrorOf(res.Err)
+		cr.Recommendation = recommendFor(res.Err, "csrutil の場所/実行可否やOSバージョン差を確認")
 		return cr
 	}
 
 	// "enabled" を含んでいれば pass とする（言語/表記差を吸収）
 	if strings.Contains(strings.ToLower(res.Stdout), "enabled") {
 		cr.Status = "pass"
-		cr.Score = weight
 	} else {
 		cr.Status = "fail"
-		cr.Score = 0
 		cr.Recommendation = "SIP 有効化にはリカバリモードでの操作が必要"
 	}
 
@@ -17,7 +17,8 @@ func TestSIP_Pass(t *testing.T) {
 	t.Cleanup(func() { runCommand = orig })
 
 	cr := SIP(context.Background())
-	if cr.Status != "pass" || cr.Score == 0 {
+	// 点数は scoring で付けるため、チェック自身は Score を設定しない
+	if cr.Status != "pass" || cr.Score != 0 {
 		t.Fatalf("SIP pass expected, got status=%s score=%d", cr.Status, cr.Score)
 	}
 }
@@ -0,0 +1,194 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/samuraidays/macinsight/internal/checks"
+	"github.com/samuraidays/macinsight/internal/output"
+	"github.com/samuraidays/macinsight/internal/scoring"
+)
+
+// audit の既定値をまとめた設定
+type Config struct {
+	Format        string                       `yaml:"format"`             // 出力形式（table | json | ndjson | sarif | junit | html | markdown）
+	Template      string                       `yaml:"template,omitempty"` // 出力に使う text/template ファイル（指定時は format より優先）
+	Sort          string                       `yaml:"sort,omitempty"`     // チェック結果の並び順（id | severity | status | score、空なら登録順）
+	Timeout       time.Duration                `yaml:"timeout"`            // チェックごとのタイムアウト
+	Timeouts      map[string]time.Duration     `yaml:"timeouts,omitempty"` // チェック別タイムアウト
+	Deadline      time.Duration                `yaml:"deadline"`           // audit 全体の制限時間（0 で無制限）
+	Parallel      int                          `yaml:"parallel"`           // 同時に実行するチェック数の上限（0 で無制限）
+	Only          []string                     `yaml:"only,omitempty"`     // 実行するチェック
+	Exclude       []string                     `yaml:"exclude,omitempty"`  // 実行しないチェック
+	UnknownPolicy string                       `yaml:"unknown_policy"`     // unknown 結果の採点方法
+	Weights       map[string]int               `yaml:"weights,omitempty"`  // チェック別の配点上書き
+	Params        map[string]map[string]string `yaml:"params,omitempty"`   // チェック別パラメータ
+	Rules         []string                     `yaml:"rules,omitempty"`    // ルールファイルのディレクトリ
+	Plugins       []string                     `yaml:"plugins,omitempty"`  // プラグインのディレクトリ
+	PluginsPath   bool                         `yaml:"plugins_path"`       // PATH 上の macinsight-check-* も使う
+	FailOn        string                       `yaml:"fail_on,omitempty"`  // このステータス以上があれば違反（fail | warn | unknown）
+	MinScore      int                          `yaml:"min_score"`          // スコアがこれ未満なら違反（0 で無効）
+	Waivers       string                       `yaml:"waivers,omitempty"`  // 例外承認ファイル
+	Profile       string                       `yaml:"profile,omitempty"`  // プロファイル名またはファイルパス
+	RequireRoot   bool                         `yaml:"require_root"`       // root 権限が必要なチェックがあれば root 以外では実行しない
+}
+
+// FailOnLevels は fail_on に指定できる値（右ほど厳しい）
+var FailOnLevels = []string{"fail", "warn", "unknown"}
+
+// Default は組み込みの既定設定を返す
+func Default() Config {
+	return Config{
+		Format:        "table",
+		Timeout:       3 * time.Second,
+		UnknownPolicy: scoring.UnknownPartial,
+	}
+}
+
+// Dir は macinsight の設定ディレクトリ（$XDG_CONFIG_HOME/macinsight、未設定時は ~/.config/macinsight）
+func Dir() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "macinsight")
+}
+
+// DefaultPath は既定の設定ファイルパス（<Dir>/config.yaml）
+func DefaultPath() string {
+	dir := Dir()
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "config.yaml")
+}
+
+// Load は既定値に設定ファイルの内容を重ねて返す
+// path が空なら DefaultPath を使い、ファイルが無ければ既定値のまま
+func Load(path string) (Config, error) {
+	cfg := Default()
+
+	explicit := path != ""
+	if !explicit {
+		path = DefaultPath()
+		if path == "" {
+			return cfg, nil
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !explicit && errors.Is(err, fs.ErrNotExist) {
+			return cfg, nil
+		}
+		return cfg, fmt.Errorf("failed to read config %s: %w", path, err)
+	}
+
+	// 未知のキー（誤記）は無視せずエラーにする、空のファイルは上書き無し
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+		return cfg, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return cfg, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// Validate は設定値の妥当性を検証する
+func (c Config) Validate() error {
+	if !output.IsFormat(c.Format) {
+		return fmt.Errorf("invalid format %q (want one of %v)", c.Format, output.Formats)
+	}
+	if !output.IsSortKey(c.Sort) {
+		return fmt.Errorf("invalid sort %q (want one of %v)", c.Sort, output.SortKeys)
+	}
+	if c.Timeout <= 0 {
+		return fmt.Errorf("timeout must be positive, got %s", c.Timeout)
+	}
+	for id, d := range c.Timeouts {
+		if d <= 0 {
+			return fmt.Errorf("timeout for %s must be positive, got %s", id, d)
+		}
+	}
+	if c.Deadline < 0 {
+		return fmt.Errorf("deadline must not be negative, got %s", c.Deadline)
+	}
+	if c.Parallel < 0 {
+		return fmt.Errorf("parallel must not be negative, got %d", c.Parallel)
+	}
+	if err := scoring.ValidatePolicy(c.UnknownPolicy); err != nil {
+		return err
+	}
+	if c.FailOn != "" && !contains(FailOnLevels, c.FailOn) {
+		return fmt.Errorf("invalid fail_on %q (want one of %v)", c.FailOn, FailOnLevels)
+	}
+	if c.MinScore < 0 || c.MinScore > 100 {
+		return fmt.Errorf("min_score must be between 0 and 100, got %d", c.MinScore)
+	}
+	for id, w := range c.Weights {
+		if w < 0 {
+			return fmt.Errorf("weight for %s must not be negative, got %d", id, w)
+		}
+	}
+	return nil
+}
+
+// ValidateChecks はチェック別の設定（weights / timeouts / params）のキーが登録済みのチェックか検証する
+// ルール・プラグインの登録後に呼ぶ（チェックIDの誤記で設定が黙って無視されるのを防ぐ）
+func (c Config) ValidateChecks() error {
+	sections := map[string][]string{}
+	for id := range c.Weights {
+		sections["weights"] = append(sections["weights"], id)
+	}
+	for id := range c.Timeouts {
+		sections["timeouts"] = append(sections["timeouts"], id)
+	}
+	for id := range c.Params {
+		sections["params"] = append(sections["params"], id)
+	}
+	for _, name := range []string{"weights", "timeouts", "params"} {
+		ids := sections[name]
+		// エラーメッセージを安定させる
+		sort.Strings(ids)
+		for _, id := range ids {
+			if _, ok := checks.Lookup(id); !ok {
+				return fmt.Errorf("%s: unknown check %q", name, id)
+			}
+		}
+	}
+	return nil
+}
+
+// Write は設定を YAML で出力する
+func (c Config) Write(w io.Writer) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(c); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+func contains(list []string, v string) bool {
+	for _, s := range list {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,119 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	return path
+}
+
+func TestLoad_MergesFileOverDefaults(t *testing.T) {
+	path := writeFile(t, `
+format: json
+timeouts:
+  osupdate: 10s
+weights:
+  filevault: 30
+params:
+  osupdate:
+    strict: true
+`)
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+	if cfg.Format != "json" {
+		t.Fatalf("format should come from file, got %q", cfg.Format)
+	}
+	if cfg.Timeout != 3*time.Second || cfg.UnknownPolicy != "partial" {
+		t.Fatalf("unset fields should keep defaults, got timeout=%s policy=%s", cfg.Timeout, cfg.UnknownPolicy)
+	}
+	if cfg.Timeouts["osupdate"] != 10*time.Second || cfg.Weights["filevault"] != 30 {
+		t.Fatalf("per-check overrides not loaded: %+v", cfg)
+	}
+	if cfg.Params["osupdate"]["strict"] != "true" {
+		t.Fatalf("params not loaded: %+v", cfg.Params)
+	}
+}
+
+func TestLoad_MissingDefaultFileIsNotAnError(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatalf("missing default config should be ignored: %v", err)
+	}
+	if cfg.Format != Default().Format {
+		t.Fatalf("expected defaults, got %+v", cfg)
+	}
+}
+
+func TestLoad_MissingExplicitFileIsAnError(t *testing.T) {
+	if _, err := Load(filepath.Join(t.TempDir(), "nope.yaml")); err == nil {
+		t.Fatal("missing explicit config should fail")
+	}
+}
+
+func TestLoad_RejectsInvalidValues(t *testing.T) {
+	for _, body := range []string{
+		"format: xml\n",
+		"unknown_policy: half\n",
+		"weights:\n  sip: -1\n",
+		"fail_on: pass\n",
+		"min_score: 101\n",
+		"parallel: -1\n",
+		"deadline: -5s\n",
+		"sort: title\n",
+	} {
+		if _, err := Load(writeFile(t, body)); err == nil {
+			t.Fatalf("config %q should be rejected", body)
+		}
+	}
+}
+
+func TestValidateChecks_RejectsUnknownCheckIDs(t *testing.T) {
+	cfg := Default()
+	cfg.Weights = map[string]int{"sip": 30}
+	cfg.Timeouts = map[string]time.Duration{"osupdate": 10 * time.Second}
+	cfg.Params = map[string]map[string]string{"osupdate": {"strict": "true"}}
+	if err := cfg.ValidateChecks(); err != nil {
+		t.Fatalf("settings for built-in checks should be valid: %v", err)
+	}
+
+	for name, c := range map[string]Config{
+		"weights":  {Weights: map[string]int{"filevalt": 10}},
+		"timeouts": {Timeouts: map[string]time.Duration{"osupdates": time.Second}},
+		"params":   {Params: map[string]map[string]string{"os-update": {"strict": "true"}}},
+	} {
+		err := c.ValidateChecks()
+		if err == nil || !strings.Contains(err.Error(), name+": unknown check") {
+			t.Errorf("%s with a misspelled check ID should be rejected, got %v", name, err)
+		}
+	}
+}
+
+func TestLoad_RejectsUnknownKeys(t *testing.T) {
+	_, err := Load(writeFile(t, "timeot: 4s\n"))
+	if err == nil || !strings.Contains(err.Error(), "timeot") {
+		t.Fatalf("misspelled key should be rejected, got %v", err)
+	}
+}
+
+func TestLoad_EmptyFileKeepsDefaults(t *testing.T) {
+	cfg, err := Load(writeFile(t, ""))
+	if err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+	if cfg.Format != Default().Format || cfg.Timeout != Default().Timeout {
+		t.Fatalf("empty config should keep defaults, got %+v", cfg)
+	}
+}
@@ -0,0 +1,101 @@
+package executil
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// Cache は1回の監査の中で同じコマンドの実行結果を共有するミドルウェア
+// 同時に同じコマンドが呼ばれた場合も実行は1回だけ（後続は完了を待つ）
+type Cache struct {
+	Debug io.Writer // nil 以外ならキャッシュヒットを書き出す
+
+	mu      sync.Mutex
+	entries map[string]*cacheEntry // cacheKey ごとの結果
+	hits    int
+	misses  int
+}
+
+type cacheEntry struct {
+	done chan struct{} // 実行が終わったら close
+	res  Result
+}
+
+func NewCache() *Cache {
+	return &Cache{entries: map[string]*cacheEntry{}}
+}
+
+// Middleware は結果をキャッシュする Middleware を返す
+// タイムアウト・中断で終わった結果は共有せず、待っていた呼び出し側で実行し直す
+func (c *Cache) Middleware() Middleware {
+	return func(next RunFunc) RunFunc {
+		var run RunFunc
+		run = func(ctx context.Context, cmd Command) Result {
+			k := cacheKey(cmd)
+
+			c.mu.Lock()
+			if e, ok := c.entries[k]; ok {
+				c.mu.Unlock()
+				select {
+				case <-e.done:
+				case <-ctx.Done():
+					return Result{ExitCode: -1, Err: classify(ctx, cmd, ctx.Err(), "")}
+				}
+				if !cacheable(e.res) {
+					return run(ctx, cmd)
+				}
+				c.hit(cmd)
+				res := e.res
+				res.Cached = true
+				return res
+			}
+			e := &cacheEntry{done: make(chan struct{})}
+			c.entries[k] = e
+			c.misses++
+			c.mu.Unlock()
+
+			e.res = next(ctx, cmd)
+			if !cacheable(e.res) {
+				c.mu.Lock()
+				delete(c.entries, k)
+				c.mu.Unlock()
+			}
+			close(e.done)
+			return e.res
+		}
+		return run
+	}
+}
+
+// Stats はヒット数と実際に実行した回数を返す
+func (c *Cache) Stats() (hits, misses int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hits, c.misses
+}
+
+func (c *Cache) hit(cmd Command) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.hits++
+	if c.Debug != nil {
+		fmt.Fprintf(c.Debug, "cache hit: %s\n", strings.Join(cmd.Argv(), " "))
+	}
+}
+
+// タイムアウト・中断は呼び出し側の事情なので共有しない
+func cacheable(res Result) bool {
+	switch KindOf(res.Err) {
+	case KindTimeout, KindKilled:
+		return false
+	}
+	return true
+}
+
+// 引数・標準入力に加え、環境変数と作業ディレクトリが同じものだけを同一とみなす
+func cacheKey(c Command) string {
+	return invocationKey(c.Argv(), c.Stdin) + "\x00\x00" + c.Dir + "\x00\x00" + strings.Join(c.Env, "\x00")
+}
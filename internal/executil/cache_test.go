@@ -0,0 +1,85 @@
+package executil
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCache_DeduplicatesConcurrentCalls(t *testing.T) {
+	var calls int32
+	slow := func(RunFunc) RunFunc {
+		return func(ctx context.Context, c Command) Result {
+			atomic.AddInt32(&calls, 1)
+			time.Sleep(20 * time.Millisecond)
+			return Result{Stdout: "ProductVersion: 15.6.1\n"}
+		}
+	}
+	var debug bytes.Buffer
+	cache := NewCache()
+	cache.Debug = &debug
+	ctx := WithMiddleware(context.Background(), slow)
+	ctx = WithMiddleware(ctx, cache.Middleware())
+
+	var wg sync.WaitGroup
+	results := make([]Result, 5)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = Run(ctx, time.Second, "/usr/bin/sw_vers")
+		}(i)
+	}
+	wg.Wait()
+
+	if calls != 1 {
+		t.Fatalf("command executed %d times, want 1", calls)
+	}
+	cached := 0
+	for _, r := range results {
+		if r.Stdout != "ProductVersion: 15.6.1\n" {
+			t.Fatalf("unexpected result %+v", r)
+		}
+		if r.Cached {
+			cached++
+		}
+	}
+	if hits, misses := cache.Stats(); hits != 4 || misses != 1 || cached != 4 {
+		t.Fatalf("hits=%d misses=%d cached=%d, want 4 1 4", hits, misses, cached)
+	}
+	if strings.Count(debug.String(), "cache hit: /usr/bin/sw_vers") != 4 {
+		t.Fatalf("debug output = %q", debug.String())
+	}
+
+	// 引数や標準入力が違えば別のコマンド
+	Run(ctx, time.Second, "/usr/bin/sw_vers", "-productVersion")
+	if calls != 2 {
+		t.Fatalf("different argv should execute, calls=%d", calls)
+	}
+}
+
+func TestCache_DoesNotShareTimeouts(t *testing.T) {
+	var calls int32
+	flaky := func(RunFunc) RunFunc {
+		return func(ctx context.Context, c Command) Result {
+			if atomic.AddInt32(&calls, 1) == 1 {
+				return Result{ExitCode: -1, Err: &Error{Kind: KindTimeout, Message: "timed out"}}
+			}
+			return Result{Stdout: "ok"}
+		}
+	}
+	cache := NewCache()
+	ctx := WithMiddleware(context.Background(), flaky)
+	ctx = WithMiddleware(ctx, cache.Middleware())
+
+	if res := Run(ctx, time.Second, "/usr/sbin/softwareupdate", "-l"); KindOf(res.Err) != KindTimeout {
+		t.Fatalf("first call should time out, got %+v", res)
+	}
+	if res := Run(ctx, time.Second, "/usr/sbin/softwareupdate", "-l"); res.Err != nil || res.Cached {
+		t.Fatalf("timeout should not be cached, got %+v", res)
+	}
+}
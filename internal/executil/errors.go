@@ -0,0 +1,108 @@
+package executil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ErrorKind はコマンド実行失敗の分類
+type ErrorKind string
+
+const (
+	KindNotFound         ErrorKind = "not_found"         // コマンドが存在しない
+	KindPermissionDenied ErrorKind = "permission_denied" // 実行権限が無い・root が必要
+	KindTimeout          ErrorKind = "timeout"           // タイムアウトで停止
+	KindExitStatus       ErrorKind = "exit_status"       // 0 以外の終了コード
+	KindKilled           ErrorKind = "killed"            // シグナル・中断で停止
+	KindOther            ErrorKind = "error"             // 上記以外（記録に無いコマンドなど）
+)
+
+// ErrorKinds は分類の一覧
+var ErrorKinds = []ErrorKind{KindNotFound, KindPermissionDenied, KindTimeout, KindExitStatus, KindKilled, KindOther}
+
+// Error は分類付きの実行エラー
+type Error struct {
+	Kind    ErrorKind
+	Message string // 利用者向けの説明（例: "/usr/bin/fdesetup requires root"）
+	Err     error  // 元のエラー
+}
+
+func (e *Error) Error() string { return e.Message }
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// KindOf はエラーの分類を返す（nil なら空、分類の無いエラーは KindOther）
+func KindOf(err error) ErrorKind {
+	if err == nil {
+		return ""
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Kind
+	}
+	return KindOther
+}
+
+// root が必要なことを示す標準エラー出力の文言（小文字で比較）
+var permissionMarkers = []string{
+	"must be run as root",
+	"must be root",
+	"requires root",
+	"need to be root",
+	"operation not permitted",
+	"permission denied",
+}
+
+// classify は実行結果のエラーを分類する
+// ctx はコマンドに渡したもの（タイムアウトと中断の区別に使う）
+func classify(ctx context.Context, c Command, err error, stderr string) error {
+	if err == nil {
+		return nil
+	}
+	name := filepath.Base(c.Name)
+
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return &Error{Kind: KindTimeout, Message: fmt.Sprintf("%s timed out after %s", name, c.Timeout.Round(time.Millisecond)), Err: err}
+	case ctx.Err() != nil:
+		return &Error{Kind: KindKilled, Message: fmt.Sprintf("%s was cancelled: %v", name, context.Cause(ctx)), Err: err}
+	case errors.Is(err, exec.ErrNotFound), errors.Is(err, fs.ErrNotExist):
+		return &Error{Kind: KindNotFound, Message: fmt.Sprintf("%s not found", c.Name), Err: err}
+	case errors.Is(err, fs.ErrPermission):
+		return &Error{Kind: KindPermissionDenied, Message: fmt.Sprintf("%s is not executable (permission denied)", c.Name), Err: err}
+	}
+
+	var ee *exec.ExitError
+	if errors.As(err, &ee) {
+		// ExitCode が -1 ならシグナルで終了している
+		if ee.ExitCode() == -1 {
+			return &Error{Kind: KindKilled, Message: fmt.Sprintf("%s was killed: %v", name, ee), Err: err}
+		}
+		lower := strings.ToLower(stderr)
+		for _, m := range permissionMarkers {
+			if strings.Contains(lower, m) {
+				return &Error{Kind: KindPermissionDenied, Message: fmt.Sprintf("%s requires root: %s", name, firstLine(stderr)), Err: err}
+			}
+		}
+		msg := fmt.Sprintf("%s exited with status %d", name, ee.ExitCode())
+		if l := firstLine(stderr); l != "" {
+			msg += ": " + l
+		}
+		return &Error{Kind: KindExitStatus, Message: msg, Err: err}
+	}
+	return &Error{Kind: KindOther, Message: err.Error(), Err: err}
+}
+
+func firstLine(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		s = s[:i]
+	}
+	return strings.TrimSpace(s)
+}
@@ -0,0 +1,56 @@
+package executil
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRun_ClassifiesFailures(t *testing.T) {
+	notExec := filepath.Join(t.TempDir(), "not-executable")
+	if err := os.WriteFile(notExec, []byte("#!/bin/sh\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name    string
+		timeout time.Duration
+		argv    []string
+		want    ErrorKind
+		message string
+	}{
+		{"not found", time.Second, []string{"/nonexistent/csrutil", "status"}, KindNotFound, "/nonexistent/csrutil not found"},
+		{"not executable", time.Second, []string{notExec}, KindPermissionDenied, "permission denied"},
+		{"needs root", time.Second, []string{"/bin/sh", "-c", "echo 'Error: This command must be run as root.' >&2; exit 1"}, KindPermissionDenied, "sh requires root: Error: This command must be run as root."},
+		{"exit status", time.Second, []string{"/bin/sh", "-c", "echo boom >&2; exit 3"}, KindExitStatus, "sh exited with status 3: boom"},
+		{"timeout", 50 * time.Millisecond, []string{"/bin/sh", "-c", "exec sleep 5"}, KindTimeout, "sh timed out after 50ms"},
+		{"killed", time.Second, []string{"/bin/sh", "-c", "kill -9 $$"}, KindKilled, "sh was killed"},
+	}
+	for _, c := range cases {
+		res := Run(context.Background(), c.timeout, c.argv[0], c.argv[1:]...)
+		if got := KindOf(res.Err); got != c.want {
+			t.Errorf("%s: kind=%q (%v), want %q", c.name, got, res.Err, c.want)
+			continue
+		}
+		if !strings.Contains(res.Err.Error(), c.message) {
+			t.Errorf("%s: message=%q, want it to contain %q", c.name, res.Err.Error(), c.message)
+		}
+	}
+}
+
+func TestKindOf(t *testing.T) {
+	if KindOf(nil) != "" {
+		t.Error("nil error should have no kind")
+	}
+	if KindOf(errors.New("x")) != KindOther {
+		t.Error("plain error should be KindOther")
+	}
+	wrapped := errors.Join(errors.New("context"), &Error{Kind: KindTimeout, Message: "t"})
+	if KindOf(wrapped) != KindTimeout {
+		t.Error("wrapped *Error should keep its kind")
+	}
+}
@@ -3,32 +3,176 @@ package executil
 import (
 	"bytes"
 	"context"
+	"errors"
+	"os"
 	"os/exec"
+	"strings"
 	"time"
 )
 
+// DefaultEnv は呼び出し元の環境に上書きする既定の環境変数
+// 出力の言語・書式を固定し、チェックが英語の出力だけを解釈すれば済むようにする
+var DefaultEnv = []string{"LANG=C", "LC_ALL=C"}
+
+// DefaultMaxOutput は標準出力・標準エラー出力それぞれの既定の最大取得バイト数
+const DefaultMaxOutput = 1 << 20
+
+// 実行するコマンド
+type Command struct {
+	Name      string
+	Args      []string
+	Timeout   time.Duration
+	Stdin     string   // 標準入力に渡す内容（空なら何も渡さない）
+	Env       []string // 追加の環境変数（"KEY=value"、DefaultEnv より優先）
+	Dir       string   // 作業ディレクトリ（空なら呼び出し元と同じ）
+	MaxOutput int      // 標準出力・標準エラー出力の最大取得バイト数（0 なら DefaultMaxOutput）
+}
+
+// Argv はコマンド名と引数を1つのスライスで返す
+func (c Command) Argv() []string {
+	return append([]string{c.Name}, c.Args...)
+}
+
+// Environ は呼び出し元の環境に DefaultEnv と Env を重ねたもの（後ろほど優先）
+func (c Command) Environ() []string {
+	env := os.Environ()
+	env = append(env, DefaultEnv...)
+	return append(env, c.Env...)
+}
+
+// CommandLine は監査記録用に、上書きした環境変数と作業ディレクトリを含む実効コマンドラインを返す
+// 例: "LANG=C LC_ALL=C /usr/bin/sw_vers"
+func (c Command) CommandLine() string {
+	var parts []string
+	if c.Dir != "" {
+		parts = append(parts, "cd", quote(c.Dir), "&&")
+	}
+	for _, kv := range append(append([]string(nil), DefaultEnv...), c.Env...) {
+		parts = append(parts, quote(kv))
+	}
+	for _, a := range c.Argv() {
+		parts = append(parts, quote(a))
+	}
+	return strings.Join(parts, " ")
+}
+
+// シェルで解釈が変わる文字を含む場合だけ単一引用符で囲む
+func quote(s string) string {
+	if s != "" && !strings.ContainsAny(s, " \t\n'\"\\$`*?[]{}()<>|&;#~!") {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
 // OSコマンドをタイムアウト付きで実行する小ユーティリティ
 type Result struct {
-	Stdout string
-	Stderr string
-	Err    error
+	Stdout    string
+	Stderr    string
+	ExitCode  int           // 終了コード（起動できなかった場合は -1）
+	Duration  time.Duration // 実行にかかった時間
+	Err       error         // 失敗時は *Error（KindOf で分類を得られる）
+	Cached    bool          // Cache から返した結果か
+	Truncated bool          // MaxOutput を超えた出力を切り捨てたか
+}
+
+// RunFunc はコマンドを実行して結果を返す関数
+type RunFunc func(ctx context.Context, c Command) Result
+
+// Middleware は RunFunc を包んで記録・再生などの処理を差し込む
+type Middleware func(next RunFunc) RunFunc
+
+type middlewareKey struct{}
+
+// WithMiddleware は context にミドルウェアを追加する
+// 後から追加したものほど外側（チェックに近い側）で動く
+func WithMiddleware(ctx context.Context, mw Middleware) context.Context {
+	mws, _ := ctx.Value(middlewareKey{}).([]Middleware)
+	next := append(append([]Middleware(nil), mws...), mw)
+	return context.WithValue(ctx, middlewareKey{}, next)
 }
 
 func Run(ctx context.Context, timeout time.Duration, name string, args ...string) Result {
-	cctx, cancel := context.WithTimeout(ctx, timeout)
+	return RunCommand(ctx, Command{Name: name, Args: args, Timeout: timeout})
+}
+
+// RunCommand は context のミドルウェアを通してコマンドを実行する
+func RunCommand(ctx context.Context, c Command) Result {
+	run := RunFunc(execute)
+	mws, _ := ctx.Value(middlewareKey{}).([]Middleware)
+	for _, mw := range mws {
+		run = mw(run)
+	}
+	return run(ctx, c)
+}
+
+// 実際にプロセスを起動する
+func execute(ctx context.Context, c Command) Result {
+	cctx, cancel := context.WithTimeout(ctx, c.Timeout)
 	defer cancel()
 
-	cmd := exec.CommandContext(cctx, name, args...)
+	cmd := exec.CommandContext(cctx, c.Name, c.Args...)
+	cmd.Env = c.Environ()
+	cmd.Dir = c.Dir
+	if c.Stdin != "" {
+		cmd.Stdin = strings.NewReader(c.Stdin)
+	}
+	// タイムアウト時は子プロセスごと止める
+	setProcessGroup(cmd)
+	cmd.WaitDelay = waitDelay
 
-	var out, errb bytes.Buffer
-	cmd.Stdout = &out
-	cmd.Stderr = &errb
+	limit := c.MaxOutput
+	if limit <= 0 {
+		limit = DefaultMaxOutput
+	}
+	out := &limitedBuffer{limit: limit}
+	errb := &limitedBuffer{limit: limit}
+	cmd.Stdout = out
+	cmd.Stderr = errb
 
+	start := time.Now()
 	err := cmd.Run()
 
 	return Result{
-		Stdout: out.String(),
-		Stderr: errb.String(),
-		Err:    err,
+		Stdout:    out.String(),
+		Stderr:    errb.String(),
+		ExitCode:  exitCode(err),
+		Duration:  time.Since(start),
+		Err:       classify(cctx, c, err, errb.String()),
+		Truncated: out.truncated || errb.truncated,
+	}
+}
+
+// 停止後にパイプを閉じるまで待つ時間（孫プロセスが出力を握っている場合の保険）
+const waitDelay = time.Second
+
+// limitedBuffer は limit バイトまで保持し、超えた分は読み捨てる
+type limitedBuffer struct {
+	buf       bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if rest := b.limit - b.buf.Len(); rest < len(p) {
+		b.truncated = true
+		if rest > 0 {
+			b.buf.Write(p[:rest])
+		}
+		// 書き込みは成功扱いにしてプロセスを止めない
+		return len(p), nil
+	}
+	return b.buf.Write(p)
+}
+
+func (b *limitedBuffer) String() string { return b.buf.String() }
+
+func exitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var ee *exec.ExitError
+	if errors.As(err, &ee) {
+		return ee.ExitCode()
 	}
+	return -1
 }
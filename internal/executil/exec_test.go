@@ -0,0 +1,63 @@
+package executil
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRun_PinsLocaleAndAppliesEnvAndDir(t *testing.T) {
+	t.Setenv("LANG", "ja_JP.UTF-8")
+	t.Setenv("LC_ALL", "ja_JP.UTF-8")
+
+	res := Run(context.Background(), time.Second, "/bin/sh", "-c", "echo $LANG $LC_ALL")
+	if got := strings.TrimSpace(res.Stdout); got != "C C" {
+		t.Fatalf("locale = %q, want C C", got)
+	}
+
+	dir := t.TempDir()
+	res = RunCommand(context.Background(), Command{
+		Name:    "/bin/sh",
+		Args:    []string{"-c", "echo $LC_ALL; pwd"},
+		Timeout: time.Second,
+		Env:     []string{"LC_ALL=en_US.UTF-8"},
+		Dir:     dir,
+	})
+	lines := strings.Split(strings.TrimSpace(res.Stdout), "\n")
+	if len(lines) != 2 || lines[0] != "en_US.UTF-8" || !strings.HasSuffix(lines[1], dir) {
+		t.Fatalf("env/dir not applied: %q", res.Stdout)
+	}
+}
+
+func TestRun_LimitsCapturedOutput(t *testing.T) {
+	res := RunCommand(context.Background(), Command{
+		Name:      "/bin/sh",
+		Args:      []string{"-c", "printf '0123456789abcdef'"},
+		Timeout:   time.Second,
+		MaxOutput: 10,
+	})
+	if res.Err != nil || res.Stdout != "0123456789" || !res.Truncated {
+		t.Fatalf("stdout=%q truncated=%v err=%v, want first 10 bytes", res.Stdout, res.Truncated, res.Err)
+	}
+}
+
+func TestRun_TimeoutKillsProcessGroup(t *testing.T) {
+	// sh が起動した孫プロセス（sleep）が出力を握っていても待たされない
+	start := time.Now()
+	res := Run(context.Background(), 100*time.Millisecond, "/bin/sh", "-c", "sleep 5; echo done")
+	if KindOf(res.Err) != KindTimeout {
+		t.Fatalf("kind = %s (%v), want timeout", KindOf(res.Err), res.Err)
+	}
+	if d := time.Since(start); d > 2*time.Second {
+		t.Fatalf("timeout took %s, child processes were not killed", d)
+	}
+}
+
+func TestCommand_CommandLine(t *testing.T) {
+	c := Command{Name: "/usr/bin/defaults", Args: []string{"read", "com.apple.screensaver", "ask For"}, Dir: "/tmp", Env: []string{"HOME=/var/root"}}
+	want := "cd /tmp && LANG=C LC_ALL=C HOME=/var/root /usr/bin/defaults read com.apple.screensaver 'ask For'"
+	if got := c.CommandLine(); got != want {
+		t.Fatalf("CommandLine = %q, want %q", got, want)
+	}
+}
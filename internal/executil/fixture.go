@@ -0,0 +1,191 @@
+package executil
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// フィクスチャバンドル内のファイル名
+const (
+	ManifestFile = "manifest.json"
+	CommandsFile = "commands.json"
+)
+
+// Manifest は記録時の環境情報
+type Manifest struct {
+	FormatVersion int       `json:"format_version"`
+	Version       string    `json:"version"` // 記録した macinsight のバージョン
+	RecordedAt    time.Time `json:"recorded_at"`
+	Hostname      string    `json:"hostname"`
+	Platform      string    `json:"platform"` // runtime.GOOS
+	Root          bool      `json:"root"`     // root 権限で記録したか
+}
+
+// Invocation は1回分のコマンド実行記録
+type Invocation struct {
+	Argv       []string `json:"argv"`
+	Stdin      string   `json:"stdin,omitempty"`
+	Stdout     string   `json:"stdout"`
+	Stderr     string   `json:"stderr"`
+	ExitCode   int      `json:"exit_code"`
+	DurationMs int64    `json:"duration_ms"`
+	Error      string   `json:"error,omitempty"`
+	ErrorKind  string   `json:"error_kind,omitempty"` // Error の分類（ErrorKind）
+}
+
+// ErrNotRecorded は再生時にバンドルに記録が無いコマンドを表す
+var ErrNotRecorded = errors.New("command not recorded in fixture")
+
+// Recorder は実行したコマンドを記録するミドルウェア
+type Recorder struct {
+	mu          sync.Mutex
+	invocations []Invocation
+}
+
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// Middleware は実行結果を記録する Middleware を返す
+func (r *Recorder) Middleware() Middleware {
+	return func(next RunFunc) RunFunc {
+		return func(ctx context.Context, c Command) Result {
+			res := next(ctx, c)
+			inv := Invocation{
+				Argv:       c.Argv(),
+				Stdin:      c.Stdin,
+				Stdout:     res.Stdout,
+				Stderr:     res.Stderr,
+				ExitCode:   res.ExitCode,
+				DurationMs: res.Duration.Milliseconds(),
+			}
+			if res.Err != nil {
+				inv.Error = res.Err.Error()
+				inv.ErrorKind = string(KindOf(res.Err))
+			}
+			r.mu.Lock()
+			r.invocations = append(r.invocations, inv)
+			r.mu.Unlock()
+			return res
+		}
+	}
+}
+
+// Save はバンドルをディレクトリに書き出す
+func (r *Recorder) Save(dir string, m Manifest) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create fixture dir %s: %w", dir, err)
+	}
+
+	m.FormatVersion = 1
+	r.mu.Lock()
+	invs := append([]Invocation(nil), r.invocations...)
+	r.mu.Unlock()
+
+	if err := writeJSONFile(filepath.Join(dir, ManifestFile), m); err != nil {
+		return err
+	}
+	return writeJSONFile(filepath.Join(dir, CommandsFile), invs)
+}
+
+// Replayer は記録済みの結果を返すミドルウェア（プロセスは起動しない）
+type Replayer struct {
+	Manifest Manifest
+
+	mu     sync.Mutex
+	queues map[string][]Invocation // argv と stdin ごとの記録（記録順）
+}
+
+// LoadReplayer はバンドルを読み込む
+func LoadReplayer(dir string) (*Replayer, error) {
+	var m Manifest
+	if err := readJSONFile(filepath.Join(dir, ManifestFile), &m); err != nil {
+		return nil, err
+	}
+	var invs []Invocation
+	if err := readJSONFile(filepath.Join(dir, CommandsFile), &invs); err != nil {
+		return nil, err
+	}
+
+	rp := &Replayer{Manifest: m, queues: map[string][]Invocation{}}
+	for _, inv := range invs {
+		k := invocationKey(inv.Argv, inv.Stdin)
+		rp.queues[k] = append(rp.queues[k], inv)
+	}
+	return rp, nil
+}
+
+// Middleware は記録から結果を返す Middleware を返す
+// 同じコマンドが複数回記録されていれば順に返し、尽きたら最後の結果を繰り返す
+func (rp *Replayer) Middleware() Middleware {
+	return func(RunFunc) RunFunc {
+		return func(ctx context.Context, c Command) Result {
+			inv, ok := rp.next(invocationKey(c.Argv(), c.Stdin))
+			if !ok {
+				return Result{ExitCode: -1, Err: fmt.Errorf("%w: %s", ErrNotRecorded, strings.Join(c.Argv(), " "))}
+			}
+			res := Result{
+				Stdout:   inv.Stdout,
+				Stderr:   inv.Stderr,
+				ExitCode: inv.ExitCode,
+				Duration: time.Duration(inv.DurationMs) * time.Millisecond,
+			}
+			if inv.Error != "" {
+				kind := ErrorKind(inv.ErrorKind)
+				if kind == "" {
+					kind = KindOther
+				}
+				res.Err = &Error{Kind: kind, Message: inv.Error}
+			}
+			return res
+		}
+	}
+}
+
+func (rp *Replayer) next(k string) (Invocation, bool) {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+
+	q := rp.queues[k]
+	if len(q) == 0 {
+		return Invocation{}, false
+	}
+	inv := q[0]
+	if len(q) > 1 {
+		rp.queues[k] = q[1:]
+	}
+	return inv, true
+}
+
+func invocationKey(argv []string, stdin string) string {
+	return strings.Join(argv, "\x00") + "\x00\x00" + stdin
+}
+
+func writeJSONFile(path string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+func readJSONFile(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return nil
+}
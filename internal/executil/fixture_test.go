@@ -0,0 +1,75 @@
+package executil
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func fakeRun(stdout string) Middleware {
+	return func(RunFunc) RunFunc {
+		return func(ctx context.Context, c Command) Result {
+			return Result{Stdout: stdout, Duration: 5 * time.Millisecond}
+		}
+	}
+}
+
+func TestRecorder_SaveAndReplay(t *testing.T) {
+	dir := t.TempDir()
+
+	rec := NewRecorder()
+	ctx := WithMiddleware(context.Background(), fakeRun("first\n"))
+	ctx = WithMiddleware(ctx, rec.Middleware())
+	Run(ctx, time.Second, "/usr/bin/tool", "status")
+
+	if err := rec.Save(dir, Manifest{Hostname: "mac01", Platform: "darwin"}); err != nil {
+		t.Fatalf("Save error: %v", err)
+	}
+
+	rp, err := LoadReplayer(dir)
+	if err != nil {
+		t.Fatalf("LoadReplayer error: %v", err)
+	}
+	if rp.Manifest.Hostname != "mac01" || rp.Manifest.Platform != "darwin" {
+		t.Fatalf("manifest mismatch: %+v", rp.Manifest)
+	}
+
+	rctx := WithMiddleware(context.Background(), rp.Middleware())
+	res := Run(rctx, time.Second, "/usr/bin/tool", "status")
+	if res.Err != nil || res.Stdout != "first\n" {
+		t.Fatalf("replay mismatch: %+v", res)
+	}
+
+	// 記録の無いコマンドは ErrNotRecorded
+	res = Run(rctx, time.Second, "/usr/bin/tool", "other")
+	if !errors.Is(res.Err, ErrNotRecorded) {
+		t.Fatalf("expected ErrNotRecorded, got %v", res.Err)
+	}
+}
+
+func TestRecorder_ReplayKeepsErrorKind(t *testing.T) {
+	dir := t.TempDir()
+
+	rec := NewRecorder()
+	failing := func(RunFunc) RunFunc {
+		return func(ctx context.Context, c Command) Result {
+			return Result{ExitCode: 1, Err: &Error{Kind: KindPermissionDenied, Message: "fdesetup requires root"}}
+		}
+	}
+	ctx := WithMiddleware(context.Background(), failing)
+	ctx = WithMiddleware(ctx, rec.Middleware())
+	Run(ctx, time.Second, "/usr/bin/fdesetup", "list")
+	if err := rec.Save(dir, Manifest{}); err != nil {
+		t.Fatalf("Save error: %v", err)
+	}
+
+	rp, err := LoadReplayer(dir)
+	if err != nil {
+		t.Fatalf("LoadReplayer error: %v", err)
+	}
+	res := Run(WithMiddleware(context.Background(), rp.Middleware()), time.Second, "/usr/bin/fdesetup", "list")
+	if KindOf(res.Err) != KindPermissionDenied || res.Err.Error() != "fdesetup requires root" {
+		t.Fatalf("replayed error = %v (%s), want permission_denied", res.Err, KindOf(res.Err))
+	}
+}
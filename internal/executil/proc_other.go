@@ -0,0 +1,8 @@
+//go:build !unix
+
+package executil
+
+import "os/exec"
+
+// プロセスグループを扱えない環境では exec.CommandContext の既定の停止（本体のみ）に任せる
+func setProcessGroup(cmd *exec.Cmd) {}
@@ -0,0 +1,17 @@
+//go:build unix
+
+package executil
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// setProcessGroup は子プロセスを新しいプロセスグループで起動し、
+// 停止時はグループ全体に SIGKILL を送る（sh -c から起動した孫プロセスも止める）
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
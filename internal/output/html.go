@@ -0,0 +1,41 @@
+package output
+
+import (
+	"embed"
+	"fmt"
+	"html/template"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/samuraidays/macinsight/pkg/types"
+)
+
+// HTML レポートのテンプレートとスタイル（外部アセットを使わず1ファイルに埋め込む）
+//
+//go:embed templates/report.html.tmpl templates/report.css
+var htmlAssets embed.FS
+
+// スコアゲージの半径
+const gaugeRadius = 52
+
+var htmlTemplate = template.Must(template.New("report.html.tmpl").Funcs(template.FuncMap{
+	"css": func() template.CSS {
+		b, _ := htmlAssets.ReadFile("templates/report.css")
+		return template.CSS(b)
+	},
+	"gaugeRadius": func() int { return gaugeRadius },
+	// 円周のうちスコアの割合だけ線を引く
+	"gaugeDash": func(score int) string {
+		c := 2 * math.Pi * gaugeRadius
+		return fmt.Sprintf("%.2f %.2f", c*float64(score)/100, c)
+	},
+	"osLabel":   osLabel,
+	"join":      strings.Join,
+	"timestamp": timestamp,
+}).ParseFS(htmlAssets, "templates/report.html.tmpl"))
+
+// レポートを単体で閲覧できる HTML で出力（CSS 埋め込み、外部アセット無し）
+func WriteHTML(w io.Writer, r types.Report) error {
+	return htmlTemplate.Execute(w, r)
+}
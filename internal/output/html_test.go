@@ -0,0 +1,60 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/samuraidays/macinsight/pkg/types"
+)
+
+func TestWriteHTML_RendersSelfContainedReport(t *testing.T) {
+	start := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	rep := types.Report{
+		Version:    "vtest",
+		Host:       types.HostInfo{Hostname: "mac-01", Serial: "C02TEST", OS: types.OSInfo{Product: "macOS", Version: "15.6.1", Build: "24G90"}},
+		StartedAt:  start,
+		FinishedAt: start.Add(time.Second),
+		Score:      50,
+		Grade:      "F",
+		Categories: []types.CategoryScore{{Category: "encryption", Score: 0, MaxScore: 20}},
+		Checks: []types.CheckResult{
+			{ID: "sip", Title: "System Integrity Protection enabled", Status: "pass", Score: 20, Weight: 20},
+			{ID: "filevault", Title: "FileVault enabled", Status: "fail", Severity: "critical", Weight: 20,
+				Evidence:       map[string]string{"fdesetup": "<script>alert(1)</script>"},
+				Recommendation: "FileVault 有効化を検討"},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteHTML(&buf, rep); err != nil {
+		t.Fatalf("WriteHTML error: %v", err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		"<!DOCTYPE html>",
+		"<style>", ".card {", // CSS を埋め込む
+		"mac-01", "C02TEST", "macOS 15.6.1 (24G90)",
+		"GRADE F", `stroke-dasharray="163.36 326.73"`,
+		`class="card status-fail" id="check-filevault"`,
+		`class="card status-pass" id="check-sip"`,
+		"FileVault 有効化を検討",
+		"encryption",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in output", want)
+		}
+	}
+	// 証跡はエスケープされる
+	if strings.Contains(out, "<script>alert(1)</script>") || !strings.Contains(out, "&lt;script&gt;") {
+		t.Error("evidence should be HTML-escaped")
+	}
+	// 外部アセットを参照しない
+	for _, ext := range []string{"<link", "<script", "src=", "url(", "http://", "https://"} {
+		if strings.Contains(out, ext) {
+			t.Errorf("output should not reference external assets, found %q", ext)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package output
 import (
 	"bytes"
 	"encoding/json"
+	"strings"
 	"testing"
 
 	"github.com/samuraidays/macinsight/pkg/types"
@@ -34,3 +35,25 @@ func TestWriteJSON_EncodesReport(t *testing.T) {
 		t.Fatalf("decoded checks mismatch: %+v", got.Checks)
 	}
 }
+
+func TestWriteJSON_EvidenceKeysAreSorted(t *testing.T) {
+	rep := types.Report{Checks: []types.CheckResult{{ID: "osupdate", Evidence: map[string]string{"version": "26.0.1", "available": "yes", "strict": "false"}}}}
+
+	// 出力を比較して差分が出ないよう、何度出力しても同じ順序になる
+	var first bytes.Buffer
+	if err := WriteJSON(&first, rep); err != nil {
+		t.Fatalf("WriteJSON error: %v", err)
+	}
+	out := first.String()
+	a, s, v := strings.Index(out, `"available"`), strings.Index(out, `"strict"`), strings.Index(out, `"version": "26.0.1"`)
+	if a < 0 || !(a < s && s < v) {
+		t.Fatalf("evidence keys not sorted: %s", out)
+	}
+	for i := 0; i < 10; i++ {
+		var buf bytes.Buffer
+		_ = WriteJSON(&buf, rep)
+		if buf.String() != out {
+			t.Fatalf("JSON output is not stable:\n%s\n%s", out, buf.String())
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package output
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/samuraidays/macinsight/pkg/types"
+)
+
+// JUnit で skipped として扱うステータス（評価できなかった・採点対象外のもの）
+var junitSkipped = map[string]bool{
+	"unknown":        true,
+	"skipped":        true,
+	"cancelled":      true,
+	"waived":         true,
+	"not_applicable": true,
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name       string          `xml:"name,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Errors     int             `xml:"errors,attr"`
+	Skipped    int             `xml:"skipped,attr"`
+	Time       string          `xml:"time,attr"`
+	Timestamp  string          `xml:"timestamp,attr"`
+	Hostname   string          `xml:"hostname,attr"`
+	Properties []junitProperty `xml:"properties>property"`
+	Cases      []junitTestCase `xml:"testcase"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+	SystemOut *junitOutput  `xml:"system-out,omitempty"`
+}
+
+// 改行を保つため CDATA で出力する
+type junitOutput struct {
+	Text string `xml:",cdata"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr,omitempty"`
+	Type    string `xml:"type,attr,omitempty"`
+	Text    string `xml:",chardata"`
+}
+
+// レポートを JUnit XML で出力（1レポート = 1 testsuite、1チェック = 1 testcase）
+// fail は failure、評価できなかったもの・採点対象外は skipped、pass / warn は成功扱い
+func WriteJUnit(w io.Writer, r types.Report) error {
+	suite := junitTestSuite{
+		Name:      "macinsight",
+		Time:      seconds(r.DurationMs),
+		Timestamp: r.StartedAt.Format("2006-01-02T15:04:05"),
+		Hostname:  r.Host.Hostname,
+	}
+	// 空の値は出さない
+	for _, p := range []junitProperty{
+		{Name: "version", Value: r.Version},
+		{Name: "os", Value: osLabel(r.Host.OS)},
+		{Name: "serial", Value: r.Host.Serial},
+		{Name: "profile", Value: r.Profile},
+		{Name: "score", Value: fmt.Sprintf("%d", r.Score)},
+		{Name: "grade", Value: r.Grade},
+	} {
+		if p.Value != "" {
+			suite.Properties = append(suite.Properties, p)
+		}
+	}
+
+	for _, c := range r.Checks {
+		tc := junitTestCase{
+			Name:      c.ID,
+			Classname: "macinsight." + categoryOf(c),
+			Time:      seconds(c.DurationMs),
+			SystemOut: &junitOutput{Text: junitSystemOut(c)},
+		}
+		switch {
+		case c.Status == "fail":
+			tc.Failure = &junitMessage{Message: c.Title + ": fail", Type: "fail", Text: c.Recommendation}
+			suite.Failures++
+		case junitSkipped[c.Status]:
+			msg := c.Status
+			if c.Reason != "" {
+				msg += ": " + c.Reason
+			} else if c.Error != nil {
+				msg += ": " + c.Error.Message
+			}
+			tc.Skipped = &junitMessage{Message: msg}
+			suite.Skipped++
+		}
+		suite.Cases = append(suite.Cases, tc)
+	}
+	suite.Tests = len(suite.Cases)
+
+	doc := junitTestSuites{
+		Name:     suite.Name,
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Skipped:  suite.Skipped,
+		Time:     suite.Time,
+		Suites:   []junitTestSuite{suite},
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// system-out: タイトル・ステータス・理由・証跡（キー順）
+func junitSystemOut(c types.CheckResult) string {
+	lines := []string{c.Title, "status=" + c.Status}
+	if c.Reason != "" {
+		lines = append(lines, "reason="+c.Reason)
+	}
+	keys := make([]string, 0, len(c.Evidence))
+	for k := range c.Evidence {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		lines = append(lines, fmt.Sprintf("%s=%s", k, c.Evidence[k]))
+	}
+	return xmlSafe(strings.Join(lines, "\n"))
+}
+
+// XML 1.0 で使えない文字（NUL・ESC などの制御文字）を U+FFFD に置き換える
+// encoding/xml は属性と文字データは置き換えるが、CDATA はそのまま書き出すため
+func xmlSafe(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '\t', r == '\n', r == '\r':
+			return r
+		case r < 0x20, r == 0xFFFE, r == 0xFFFF, r >= 0xD800 && r <= 0xDFFF:
+			return '\uFFFD'
+		}
+		return r
+	}, s)
+}
+
+func categoryOf(c types.CheckResult) string {
+	if c.Category == "" {
+		return "uncategorized"
+	}
+	return c.Category
+}
+
+// ミリ秒を JUnit の time 属性（秒）にする
+func seconds(ms int64) string {
+	return fmt.Sprintf("%.3f", float64(ms)/1000)
+}
@@ -0,0 +1,110 @@
+package output
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/samuraidays/macinsight/pkg/types"
+)
+
+func TestWriteJUnit_MapsStatusesToTestcases(t *testing.T) {
+	start := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	rep := types.Report{
+		Version:    "vtest",
+		Host:       types.HostInfo{Hostname: "host"},
+		StartedAt:  start,
+		DurationMs: 8123,
+		Score:      50,
+		Grade:      "F",
+		Checks: []types.CheckResult{
+			{ID: "sip", Title: "System Integrity Protection enabled", Status: "pass", Category: "system_integrity", DurationMs: 25},
+			{ID: "filevault", Title: "FileVault enabled", Status: "fail", Category: "encryption", DurationMs: 40,
+				Evidence: map[string]string{"fdesetup": "FileVault is Off."}, Recommendation: "FileVault 有効化を検討"},
+			{ID: "osupdate", Title: "OS updates current", Status: "unknown", Category: "patching",
+				Error: &types.CheckError{Kind: "timeout", Message: "softwareupdate timed out after 8s"}},
+			{ID: "firewall", Title: "Firewall enabled", Status: "skipped", Reason: "requires root (run with sudo)"},
+			{ID: "autologin", Title: "Auto-login disabled", Status: "warn", Category: "authentication"},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteJUnit(&buf, rep); err != nil {
+		t.Fatalf("WriteJUnit error: %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "<?xml") {
+		t.Fatalf("missing XML header: %s", buf.String())
+	}
+
+	var doc junitTestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("output is not valid XML: %v\n%s", err, buf.String())
+	}
+	if len(doc.Suites) != 1 {
+		t.Fatalf("want one testsuite per report, got %d", len(doc.Suites))
+	}
+	s := doc.Suites[0]
+	if s.Tests != 5 || s.Failures != 1 || s.Skipped != 2 || s.Time != "8.123" || s.Hostname != "host" || s.Timestamp != "2026-10-18T09:00:00" {
+		t.Fatalf("testsuite attributes = %+v", s)
+	}
+
+	cases := map[string]junitTestCase{}
+	for _, tc := range s.Cases {
+		cases[tc.Name] = tc
+	}
+	if fv := cases["filevault"]; fv.Failure == nil || fv.Failure.Text != "FileVault 有効化を検討" || fv.Time != "0.040" ||
+		fv.Classname != "macinsight.encryption" || fv.SystemOut == nil || !strings.Contains(fv.SystemOut.Text, "status=fail\nfdesetup=FileVault is Off.") {
+		t.Fatalf("filevault testcase = %+v", fv)
+	}
+	if ou := cases["osupdate"]; ou.Skipped == nil || !strings.Contains(ou.Skipped.Message, "timed out") {
+		t.Fatalf("unknown should be skipped with the error: %+v", ou)
+	}
+	if fw := cases["firewall"]; fw.Skipped == nil || !strings.Contains(fw.Skipped.Message, "requires root") || fw.Classname != "macinsight.uncategorized" {
+		t.Fatalf("skipped testcase = %+v", fw)
+	}
+	for _, id := range []string{"sip", "autologin"} {
+		if tc := cases[id]; tc.Failure != nil || tc.Skipped != nil {
+			t.Errorf("%s should pass: %+v", id, tc)
+		}
+	}
+}
+
+func TestWriteJUnit_ReplacesInvalidXMLCharacters(t *testing.T) {
+	rep := types.Report{Checks: []types.CheckResult{
+		{ID: "osupdate", Title: "OS updates current", Status: "fail",
+			Reason:         "bell\a",
+			Evidence:       map[string]string{"updates": "\x1b[1mSecurity\x1b[0m\x00 ]]> \xff"},
+			Recommendation: "update\x0bnow"},
+		{ID: "plugin", Title: "Plugin", Status: "unknown",
+			Error: &types.CheckError{Kind: "error", Message: "bad\x00output"}},
+	}}
+
+	var buf bytes.Buffer
+	if err := WriteJUnit(&buf, rep); err != nil {
+		t.Fatalf("WriteJUnit error: %v", err)
+	}
+	out := buf.String()
+	for _, c := range []string{"\x00", "\x1b", "\a", "\x0b", "\xff"} {
+		if strings.Contains(out, c) {
+			t.Errorf("output contains invalid XML character %q", c)
+		}
+	}
+
+	// CI のパーサーと同じく、文書全体を最後まで読めること
+	dec := xml.NewDecoder(&buf)
+	for {
+		_, err := dec.Token()
+		if err != nil {
+			if err != io.EOF {
+				t.Fatalf("output is not well-formed XML: %v\n%s", err, out)
+			}
+			break
+		}
+	}
+	if !strings.Contains(out, "�[1mSecurity�[0m� ]]") {
+		t.Errorf("control characters should be replaced with U+FFFD:\n%s", out)
+	}
+}
@@ -0,0 +1,131 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/samuraidays/macinsight/pkg/types"
+)
+
+// レポートを Markdown（GitHub Flavored Markdown）で出力
+// チケットや PR にそのまま貼れるよう、概要・チェック一覧・fail の詳細（折りたたみ）を出す
+func WriteMarkdown(w io.Writer, r types.Report) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "## macinsight report: %s\n\n", mdText(r.Host.Hostname))
+	b.WriteString("| | |\n|---|---|\n")
+	for _, row := range [][2]string{
+		{"Host", r.Host.Hostname},
+		{"Serial", r.Host.Serial},
+		{"OS", osLabel(r.Host.OS)},
+		{"Profile", r.Profile},
+		{"Score", fmt.Sprintf("**%d** / 100 (GRADE %s, %d/%d points)", r.Score, r.Grade, r.EarnedScore, r.MaxScore)},
+		{"Started", timestamp(r.StartedAt)},
+		{"Version", r.Version},
+	} {
+		// 取れなかった項目は省く
+		if row[1] != "" {
+			fmt.Fprintf(&b, "| %s | %s |\n", row[0], mdCell(row[1]))
+		}
+	}
+
+	// チェック一覧（レポートの順序のまま）
+	b.WriteString("\n### Checks\n\n")
+	b.WriteString("| Check | ID | Severity | Status | Score |\n|---|---|---|---|---:|\n")
+	for _, c := range r.Checks {
+		fmt.Fprintf(&b, "| %s | `%s` | %s | %s | %d/%d |\n", mdCell(c.Title), c.ID, c.Severity, mdStatus(c.Status), c.Score, c.Weight)
+	}
+
+	if len(r.Categories) > 0 {
+		b.WriteString("\n### Categories\n\n")
+		b.WriteString("| Category | Score |\n|---|---:|\n")
+		for _, c := range r.Categories {
+			fmt.Fprintf(&b, "| %s | %d (%d/%d) |\n", mdCell(c.Category), c.Score, c.EarnedScore, c.MaxScore)
+		}
+	}
+
+	if len(r.Compliance) > 0 {
+		b.WriteString("\n### Compliance\n\n")
+		b.WriteString("| Framework | Passed | Pass Rate | Controls |\n|---|---:|---:|---|\n")
+		for _, c := range r.Compliance {
+			fmt.Fprintf(&b, "| %s | %d/%d | %d%% | %s |\n", mdCell(c.Framework), c.Passed, c.Total, c.PassRate, mdCell(strings.Join(c.Controls, ", ")))
+		}
+	}
+
+	// fail の証跡と推奨対応
+	var failed []types.CheckResult
+	for _, c := range r.Checks {
+		if c.Status == "fail" {
+			failed = append(failed, c)
+		}
+	}
+	if len(failed) > 0 {
+		b.WriteString("\n### Failed checks\n")
+		for _, c := range failed {
+			writeMarkdownDetails(&b, c)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// 1チェック分の折りたたみ（<details>）を書く
+func writeMarkdownDetails(b *strings.Builder, c types.CheckResult) {
+	summary := fmt.Sprintf("<b>%s</b> (<code>%s</code>", mdText(c.Title), mdText(c.ID))
+	if c.Severity != "" {
+		summary += ", " + c.Severity
+	}
+	summary += ")"
+	fmt.Fprintf(b, "\n<details>\n<summary>%s</summary>\n\n", summary)
+
+	if c.Reason != "" {
+		fmt.Fprintf(b, "Reason: %s\n\n", mdText(c.Reason))
+	}
+	if c.Error != nil {
+		fmt.Fprintf(b, "Error (%s): %s\n\n", c.Error.Kind, mdText(c.Error.Message))
+	}
+	if len(c.Evidence) > 0 {
+		keys := make([]string, 0, len(c.Evidence))
+		for k := range c.Evidence {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		b.WriteString("| Evidence | Value |\n|---|---|\n")
+		for _, k := range keys {
+			fmt.Fprintf(b, "| %s | %s |\n", mdCell(k), mdCell(c.Evidence[k]))
+		}
+		b.WriteString("\n")
+	}
+	if c.Recommendation != "" {
+		fmt.Fprintf(b, "**Recommendation:** %s\n\n", mdText(c.Recommendation))
+	}
+	b.WriteString("</details>\n")
+}
+
+// 目で追いやすいようステータスに記号を付ける
+func mdStatus(status string) string {
+	switch status {
+	case "pass":
+		return "✅ pass"
+	case "fail":
+		return "❌ fail"
+	case "warn":
+		return "⚠️ warn"
+	}
+	return status
+}
+
+// 本文用: 証跡などに含まれる HTML タグがそのまま解釈されないようにする
+func mdText(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
+
+// 表のセル用: 区切りの "|" と改行もエスケープする
+func mdCell(s string) string {
+	s = strings.ReplaceAll(mdText(s), "|", `\|`)
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	return strings.ReplaceAll(strings.TrimRight(s, "\n"), "\n", "<br>")
+}
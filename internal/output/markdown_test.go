@@ -0,0 +1,55 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/samuraidays/macinsight/pkg/types"
+)
+
+func TestWriteMarkdown_SummaryTableAndFailureDetails(t *testing.T) {
+	rep := types.Report{
+		Version: "vtest",
+		Host:    types.HostInfo{Hostname: "mac-01", OS: types.OSInfo{Product: "macOS", Version: "15.6.1", Build: "24G90"}},
+		Score:   50, MaxScore: 40, EarnedScore: 20, Grade: "F",
+		Checks: []types.CheckResult{
+			{ID: "sip", Title: "System Integrity Protection enabled", Status: "pass", Score: 20, Weight: 20},
+			{ID: "filevault", Title: "FileVault enabled", Status: "fail", Severity: "critical", Weight: 20,
+				Evidence:       map[string]string{"fdesetup": "a|b\n<c>"},
+				Recommendation: "FileVault 有効化を検討"},
+			{ID: "autologin", Title: "Auto login disabled", Status: "warn", Score: 10, Weight: 20,
+				Recommendation: "not shown"},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteMarkdown(&buf, rep); err != nil {
+		t.Fatalf("WriteMarkdown error: %v", err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		"| Host | mac-01 |",
+		"| OS | macOS 15.6.1 (24G90) |",
+		"| Score | **50** / 100 (GRADE F, 20/40 points) |",
+		"| Check | ID | Severity | Status | Score |",
+		"| FileVault enabled | `filevault` | critical | ❌ fail | 0/20 |",
+		"| System Integrity Protection enabled | `sip` |  | ✅ pass | 20/20 |",
+		"<summary><b>FileVault enabled</b> (<code>filevault</code>, critical)</summary>",
+		// 表を壊さないよう "|"・改行・タグをエスケープする
+		`| fdesetup | a\|b<br>&lt;c&gt; |`,
+		"**Recommendation:** FileVault 有効化を検討",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in output:\n%s", want, out)
+		}
+	}
+	// 詳細は fail のみ、取れなかった項目は省く
+	if strings.Count(out, "<details>") != 1 || strings.Contains(out, "not shown") {
+		t.Errorf("details should be rendered only for failing checks:\n%s", out)
+	}
+	if strings.Contains(out, "| Serial |") || strings.Contains(out, "| Started |") {
+		t.Errorf("empty summary rows should be omitted:\n%s", out)
+	}
+}
@@ -0,0 +1,64 @@
+package output
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/samuraidays/macinsight/pkg/types"
+)
+
+// NDJSONWriter は監査の進行イベントを1行1件の JSON で書き出す
+// runner.Option.OnEvent に Event を渡すと、監査中に逐次出力される
+type NDJSONWriter struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+	err error // 最初の書き込みエラー
+}
+
+func NewNDJSONWriter(w io.Writer) *NDJSONWriter {
+	return &NDJSONWriter{enc: json.NewEncoder(w)}
+}
+
+// Event はイベントを1行書き出す（エラー後は何もしない）
+func (n *NDJSONWriter) Event(ev types.Event) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.err != nil {
+		return
+	}
+	n.err = n.enc.Encode(ev)
+}
+
+// Err は書き込み中に起きた最初のエラーを返す
+func (n *NDJSONWriter) Err() error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.err
+}
+
+// 完了済みのレポートをイベント列として出力（再生・変換用）
+// check_started は含まず、check_finished はレポートの順に並ぶ
+func WriteNDJSON(w io.Writer, r types.Report) error {
+	n := NewNDJSONWriter(w)
+
+	info := types.RunInfo{Version: r.Version, Host: r.Host, Profile: r.Profile, Privileged: r.Privileged}
+	for _, c := range r.Checks {
+		info.Checks = append(info.Checks, c.ID)
+	}
+	n.Event(types.Event{Event: types.EventRunStarted, Time: r.StartedAt, Run: &info})
+
+	for i := range r.Checks {
+		c := r.Checks[i]
+		at := r.FinishedAt
+		if c.StartedAt != nil {
+			at = c.StartedAt.Add(time.Duration(c.DurationMs) * time.Millisecond)
+		}
+		n.Event(types.Event{Event: types.EventCheckFinished, Time: at, ID: c.ID, Result: &c})
+	}
+
+	totals := r.Totals()
+	n.Event(types.Event{Event: types.EventRunFinished, Time: r.FinishedAt, Totals: &totals})
+	return n.Err()
+}
@@ -0,0 +1,58 @@
+package output
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/samuraidays/macinsight/pkg/types"
+)
+
+func TestWriteNDJSON_EmitsOneEventPerLine(t *testing.T) {
+	start := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	rep := types.Report{
+		Version:    "vtest",
+		Host:       types.HostInfo{Hostname: "host"},
+		StartedAt:  start,
+		FinishedAt: start.Add(time.Second),
+		Score:      50,
+		Grade:      "F",
+		Checks: []types.CheckResult{
+			{ID: "sip", Status: "pass", Score: 20, Weight: 20, StartedAt: &start, DurationMs: 30},
+			{ID: "filevault", Status: "fail", Score: 0, Weight: 20},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteNDJSON(&buf, rep); err != nil {
+		t.Fatalf("WriteNDJSON error: %v", err)
+	}
+
+	var events []types.Event
+	sc := bufio.NewScanner(&buf)
+	for sc.Scan() {
+		var ev types.Event
+		if err := json.Unmarshal(sc.Bytes(), &ev); err != nil {
+			t.Fatalf("line %q is not JSON: %v", sc.Text(), err)
+		}
+		events = append(events, ev)
+	}
+
+	want := []string{types.EventRunStarted, types.EventCheckFinished, types.EventCheckFinished, types.EventRunFinished}
+	if len(events) != len(want) {
+		t.Fatalf("got %d events, want %d", len(events), len(want))
+	}
+	for i, ev := range events {
+		if ev.Event != want[i] {
+			t.Fatalf("event %d = %s, want %s", i, ev.Event, want[i])
+		}
+	}
+	if events[1].Result == nil || events[1].Result.ID != "sip" || !events[1].Time.Equal(start.Add(30*time.Millisecond)) {
+		t.Fatalf("check_finished = %+v", events[1])
+	}
+	if tot := events[3].Totals; tot == nil || tot.Score != 50 || tot.Statuses["fail"] != 1 || tot.Statuses["pass"] != 1 {
+		t.Fatalf("run_finished totals = %+v", tot)
+	}
+}
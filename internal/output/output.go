@@ -0,0 +1,67 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/samuraidays/macinsight/pkg/types"
+)
+
+// Formats は指定可能な出力形式
+var Formats = []string{"table", "json", "ndjson", "sarif", "junit", "html", "markdown"}
+
+// IsFormat は出力形式名が有効か返す
+func IsFormat(format string) bool {
+	for _, f := range Formats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// OS 情報を1行にする（例: "macOS 15.6.1 (24G90)"、取れなかった項目は省く）
+func osLabel(os types.OSInfo) string {
+	var parts []string
+	for _, p := range []string{os.Product, os.Version} {
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+	if os.Build != "" {
+		parts = append(parts, "("+os.Build+")")
+	}
+	return strings.Join(parts, " ")
+}
+
+// 時刻を人が読む形式にする（ゼロ値は空）
+func timestamp(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format("2006-01-02 15:04:05 MST")
+}
+
+// 指定形式でレポートを出力
+func Write(w io.Writer, format string, r types.Report) error {
+	switch format {
+	case "table":
+		return WriteTable(w, r)
+	case "json":
+		return WriteJSON(w, r)
+	case "ndjson":
+		return WriteNDJSON(w, r)
+	case "sarif":
+		return WriteSARIF(w, r)
+	case "junit":
+		return WriteJUnit(w, r)
+	case "html":
+		return WriteHTML(w, r)
+	case "markdown":
+		return WriteMarkdown(w, r)
+	default:
+		return fmt.Errorf("unknown output format %q", format)
+	}
+}
@@ -0,0 +1,257 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/samuraidays/macinsight/internal/checks"
+	"github.com/samuraidays/macinsight/pkg/types"
+)
+
+// SARIF 2.1.0 のスキーマとバージョン
+const (
+	sarifSchema  = "https://docs.oasis-open.org/sarif/sarif/v2.1.0/errata01/os/schemas/sarif-schema-2.1.0.json"
+	sarifVersion = "2.1.0"
+)
+
+// ステータスごとの result.level（pass は result にしない）
+var sarifLevels = map[string]string{
+	"fail":           "error",
+	"warn":           "warning",
+	"unknown":        "warning",
+	"cancelled":      "note",
+	"skipped":        "note",
+	"waived":         "note",
+	"not_applicable": "none",
+}
+
+// 重要度ごとの security-severity（GitHub code scanning の 0.0〜10.0）
+var securitySeverity = map[string]string{
+	checks.SeverityCritical: "9.5",
+	checks.SeverityHigh:     "8.0",
+	checks.SeverityMedium:   "5.5",
+	checks.SeverityLow:      "3.0",
+	checks.SeverityInfo:     "0.0",
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool        sarifTool              `json:"tool"`
+	Invocations []sarifInvocation      `json:"invocations"`
+	Results     []sarifResult          `json:"results"`
+	Properties  map[string]interface{} `json:"properties"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string                 `json:"id"`
+	ShortDescription     sarifMessage           `json:"shortDescription"`
+	FullDescription      *sarifMessage          `json:"fullDescription,omitempty"`
+	Help                 *sarifMessage          `json:"help,omitempty"`
+	DefaultConfiguration sarifRuleConfig        `json:"defaultConfiguration"`
+	Properties           map[string]interface{} `json:"properties"`
+}
+
+type sarifRuleConfig struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifInvocation struct {
+	ExecutionSuccessful bool   `json:"executionSuccessful"`
+	StartTimeUTC        string `json:"startTimeUtc"`
+	EndTimeUTC          string `json:"endTimeUtc"`
+}
+
+type sarifResult struct {
+	RuleID              string                 `json:"ruleId"`
+	RuleIndex           int                    `json:"ruleIndex"`
+	Kind                string                 `json:"kind,omitempty"`
+	Level               string                 `json:"level"`
+	Message             sarifMessage           `json:"message"`
+	PartialFingerprints map[string]string      `json:"partialFingerprints"`
+	Suppressions        []sarifSuppression     `json:"suppressions,omitempty"`
+	Properties          map[string]interface{} `json:"properties"`
+}
+
+type sarifSuppression struct {
+	Kind          string `json:"kind"`
+	Status        string `json:"status"`
+	Justification string `json:"justification,omitempty"`
+}
+
+// レポートを SARIF 2.1.0 で出力
+// チェックごとに rule を作り、pass 以外の結果を result にする
+func WriteSARIF(w io.Writer, r types.Report) error {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "macinsight",
+			Version:        r.Version,
+			InformationURI: "https://github.com/samuraidays/macinsight",
+			Rules:          []sarifRule{},
+		}},
+		Invocations: []sarifInvocation{{
+			ExecutionSuccessful: true,
+			StartTimeUTC:        r.StartedAt.UTC().Format(time.RFC3339),
+			EndTimeUTC:          r.FinishedAt.UTC().Format(time.RFC3339),
+		}},
+		Results:    []sarifResult{},
+		Properties: sarifRunProperties(r),
+	}
+
+	for i, c := range r.Checks {
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRuleFor(c))
+		if c.Status == "pass" {
+			continue
+		}
+		run.Results = append(run.Results, sarifResultFor(r.Host, i, c))
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(sarifLog{Schema: sarifSchema, Version: sarifVersion, Runs: []sarifRun{run}})
+}
+
+// ホスト情報とスコアを run の properties に載せる
+func sarifRunProperties(r types.Report) map[string]interface{} {
+	props := map[string]interface{}{
+		"hostname":     r.Host.Hostname,
+		"os":           osLabel(r.Host.OS),
+		"privileged":   r.Privileged,
+		"score":        r.Score,
+		"max_score":    r.MaxScore,
+		"earned_score": r.EarnedScore,
+		"grade":        r.Grade,
+	}
+	if r.Host.Serial != "" {
+		props["serial"] = r.Host.Serial
+	}
+	if r.Profile != "" {
+		props["profile"] = r.Profile
+	}
+	return props
+}
+
+func sarifRuleFor(c types.CheckResult) sarifRule {
+	rule := sarifRule{
+		ID:                   c.ID,
+		ShortDescription:     sarifMessage{Text: c.Title},
+		DefaultConfiguration: sarifRuleConfig{Level: ruleLevel(c.Severity)},
+		Properties:           map[string]interface{}{},
+	}
+	if def, ok := checks.Lookup(c.ID); ok && def.Description() != "" {
+		rule.FullDescription = &sarifMessage{Text: def.Description()}
+	}
+	if c.Recommendation != "" {
+		rule.Help = &sarifMessage{Text: c.Recommendation}
+	}
+
+	tags := []string{"security"}
+	if c.Category != "" {
+		tags = append(tags, c.Category)
+		rule.Properties["category"] = c.Category
+	}
+	for _, ctl := range c.Controls {
+		tags = append(tags, ctl.Framework+" "+ctl.ID)
+	}
+	rule.Properties["tags"] = tags
+	if c.Severity != "" {
+		rule.Properties["severity"] = c.Severity
+		rule.Properties["security-severity"] = securitySeverity[c.Severity]
+	}
+	return rule
+}
+
+func sarifResultFor(host types.HostInfo, ruleIndex int, c types.CheckResult) sarifResult {
+	res := sarifResult{
+		RuleID:    c.ID,
+		RuleIndex: ruleIndex,
+		Level:     sarifLevels[c.Status],
+		Message:   sarifMessage{Text: sarifMessageText(c)},
+		// 同じ端末・同じチェックの結果を実行をまたいで同一視する
+		PartialFingerprints: map[string]string{"macinsightHost/v1": hostKey(host) + ":" + c.ID},
+		Properties: map[string]interface{}{
+			"status": c.Status,
+			"score":  c.Score,
+			"weight": c.Weight,
+		},
+	}
+	if res.Level == "" {
+		res.Level = "warning"
+	}
+	if c.Status == "not_applicable" {
+		res.Kind = "notApplicable"
+	}
+	if c.Status == "waived" {
+		res.Suppressions = []sarifSuppression{{Kind: "external", Status: "accepted", Justification: c.Evidence["waiver_justification"]}}
+	}
+	if len(c.Evidence) > 0 {
+		res.Properties["evidence"] = c.Evidence
+	}
+	if c.Error != nil {
+		res.Properties["error"] = c.Error
+	}
+	return res
+}
+
+// 例: "FileVault enabled: fail (fdesetup=FileVault is Off.)"
+func sarifMessageText(c types.CheckResult) string {
+	var detail []string
+	if c.Reason != "" {
+		detail = append(detail, c.Reason)
+	}
+	keys := make([]string, 0, len(c.Evidence))
+	for k := range c.Evidence {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		detail = append(detail, fmt.Sprintf("%s=%s", k, c.Evidence[k]))
+	}
+	msg := fmt.Sprintf("%s: %s", c.Title, c.Status)
+	if len(detail) > 0 {
+		msg += " (" + strings.Join(detail, ", ") + ")"
+	}
+	return msg
+}
+
+// 重要度から rule の既定 level を決める
+func ruleLevel(severity string) string {
+	switch severity {
+	case checks.SeverityCritical, checks.SeverityHigh:
+		return "error"
+	case checks.SeverityLow, checks.SeverityInfo:
+		return "note"
+	}
+	return "warning"
+}
+
+// シリアル番号が取れなければホスト名で端末を識別する
+func hostKey(h types.HostInfo) string {
+	if h.Serial != "" {
+		return h.Serial
+	}
+	return h.Hostname
+}
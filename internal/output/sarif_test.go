@@ -0,0 +1,110 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+
+	"github.com/samuraidays/macinsight/pkg/types"
+)
+
+func sarifReport() types.Report {
+	start := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	return types.Report{
+		Version:    "vtest",
+		Host:       types.HostInfo{Hostname: "host", Serial: "C02TEST", OS: types.OSInfo{Product: "macOS", Version: "15.6.1", Build: "24G90"}},
+		StartedAt:  start,
+		FinishedAt: start.Add(9 * time.Second),
+		Score:      40,
+		Grade:      "F",
+		Checks: []types.CheckResult{
+			{ID: "sip", Title: "System Integrity Protection enabled", Status: "pass", Severity: "critical", Score: 20, Weight: 20},
+			{ID: "filevault", Title: "FileVault enabled", Status: "fail", Severity: "critical", Category: "encryption", Weight: 20,
+				Controls:       []types.Control{{Framework: "NIST SP 800-53 Rev. 5", ID: "SC-28"}},
+				Evidence:       map[string]string{"fdesetup": "FileVault is Off."},
+				Recommendation: "FileVault 有効化を検討"},
+			{ID: "osupdate", Title: "OS updates current", Status: "unknown", Severity: "high", Weight: 20,
+				Error: &types.CheckError{Kind: "timeout", Message: "softwareupdate timed out after 8s"}},
+			{ID: "autologin", Title: "Auto-login disabled", Status: "waived", Severity: "high", Weight: 10,
+				Evidence: map[string]string{"waiver_justification": "kiosk"}},
+			{ID: "firewall", Title: "Firewall enabled", Status: "not_applicable", Severity: "medium", Weight: 10, Reason: "not supported on linux"},
+		},
+	}
+}
+
+func TestWriteSARIF_ValidatesAgainstSchema(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteSARIF(&buf, sarifReport()); err != nil {
+		t.Fatalf("WriteSARIF error: %v", err)
+	}
+
+	f, err := os.Open("testdata/sarif-schema-2.1.0.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+	c := jsonschema.NewCompiler()
+	c.Draft = jsonschema.Draft4
+	if err := c.AddResource(sarifSchema, f); err != nil {
+		t.Fatalf("AddResource error: %v", err)
+	}
+	sch, err := c.Compile(sarifSchema)
+	if err != nil {
+		t.Fatalf("Compile error: %v", err)
+	}
+
+	var doc interface{}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("output is not JSON: %v", err)
+	}
+	if err := sch.Validate(doc); err != nil {
+		t.Fatalf("SARIF output does not match the schema: %v\n%s", err, buf.String())
+	}
+
+	// スキーマ検証が効いていることの確認
+	doc.(map[string]interface{})["version"] = "2.0.0"
+	if err := sch.Validate(doc); err == nil {
+		t.Fatal("schema should reject an unsupported version")
+	}
+}
+
+func TestWriteSARIF_MapsChecksToRulesAndResults(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteSARIF(&buf, sarifReport()); err != nil {
+		t.Fatalf("WriteSARIF error: %v", err)
+	}
+	var log sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatalf("json.Unmarshal error: %v", err)
+	}
+	run := log.Runs[0]
+
+	// rule はすべてのチェック、result は pass 以外
+	if len(run.Tool.Driver.Rules) != 5 || len(run.Results) != 4 {
+		t.Fatalf("rules=%d results=%d, want 5 and 4", len(run.Tool.Driver.Rules), len(run.Results))
+	}
+	fv := run.Tool.Driver.Rules[1]
+	if fv.ID != "filevault" || fv.ShortDescription.Text != "FileVault enabled" || fv.Help == nil || fv.Help.Text != "FileVault 有効化を検討" {
+		t.Fatalf("filevault rule = %+v", fv)
+	}
+
+	want := map[string]string{"filevault": "error", "osupdate": "warning", "autologin": "note", "firewall": "none"}
+	for _, res := range run.Results {
+		if res.Level != want[res.RuleID] {
+			t.Errorf("%s level = %s, want %s", res.RuleID, res.Level, want[res.RuleID])
+		}
+		if run.Tool.Driver.Rules[res.RuleIndex].ID != res.RuleID {
+			t.Errorf("%s ruleIndex %d points to %s", res.RuleID, res.RuleIndex, run.Tool.Driver.Rules[res.RuleIndex].ID)
+		}
+		if res.RuleID == "autologin" && (len(res.Suppressions) != 1 || res.Suppressions[0].Justification != "kiosk") {
+			t.Errorf("waived result should be suppressed: %+v", res.Suppressions)
+		}
+	}
+	if run.Properties["hostname"] != "host" || run.Properties["serial"] != "C02TEST" || run.Properties["grade"] != "F" {
+		t.Fatalf("run properties = %v", run.Properties)
+	}
+}
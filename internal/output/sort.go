@@ -0,0 +1,86 @@
+package output
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/samuraidays/macinsight/internal/checks"
+	"github.com/samuraidays/macinsight/internal/scoring"
+	"github.com/samuraidays/macinsight/pkg/types"
+)
+
+// SortKeys は --sort に指定できる並び順（空なら runner の順序＝登録順・プロファイル順）
+var SortKeys = []string{"id", "severity", "status", "score"}
+
+// IsSortKey は並び順の名前が有効か返す（空は既定の順序）
+func IsSortKey(key string) bool {
+	if key == "" {
+		return true
+	}
+	for _, k := range SortKeys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// 対応が必要なものから順に並べたステータス
+var statusOrder = []string{"fail", "warn", "unknown", "cancelled", "skipped", "waived", "not_applicable", "pass"}
+
+// Sort はチェック結果を key の順に並べ替えたレポートを返す（元のレポートは変更しない）
+// 同順位は元の順序を保つ
+func Sort(r types.Report, key string) (types.Report, error) {
+	var less func(a, b types.CheckResult) bool
+	switch key {
+	case "":
+		return r, nil
+	case "id":
+		less = func(a, b types.CheckResult) bool { return a.ID < b.ID }
+	case "severity":
+		// 重い順
+		less = func(a, b types.CheckResult) bool {
+			return rank(checks.Severities, a.Severity) < rank(checks.Severities, b.Severity)
+		}
+	case "status":
+		less = func(a, b types.CheckResult) bool {
+			return rank(statusOrder, a.Status) < rank(statusOrder, b.Status)
+		}
+	case "score":
+		// 失点（配点 - 得点）の大きい順、採点対象外は最後
+		less = func(a, b types.CheckResult) bool {
+			la, sa := pointsLost(a)
+			lb, sb := pointsLost(b)
+			if sa != sb {
+				return sa
+			}
+			return la > lb
+		}
+	default:
+		return r, fmt.Errorf("unknown sort key %q (want one of %v)", key, SortKeys)
+	}
+
+	list := append([]types.CheckResult(nil), r.Checks...)
+	sort.SliceStable(list, func(i, j int) bool { return less(list[i], list[j]) })
+	r.Checks = list
+	return r, nil
+}
+
+// 失点と採点対象かどうか（not_applicable / skipped / waived / cancelled は Score 0 でも失点にしない）
+// unknown はポリシーがレポートに残らないため常に採点対象として扱う
+func pointsLost(c types.CheckResult) (int, bool) {
+	if _, ok := scoring.Points(c.Status, c.Weight, scoring.UnknownPartial); !ok {
+		return 0, false
+	}
+	return c.Weight - c.Score, true
+}
+
+// 一覧に無い値は最後
+func rank(order []string, v string) int {
+	for i, s := range order {
+		if s == v {
+			return i
+		}
+	}
+	return len(order)
+}
@@ -0,0 +1,67 @@
+package output
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/samuraidays/macinsight/pkg/types"
+)
+
+func TestSort_OrdersChecksByKey(t *testing.T) {
+	rep := types.Report{Checks: []types.CheckResult{
+		{ID: "osupdate", Severity: "high", Status: "warn", Score: 10, Weight: 20},
+		{ID: "sip", Severity: "critical", Status: "pass", Score: 20, Weight: 20},
+		{ID: "firewall", Severity: "medium", Status: "fail", Score: 0, Weight: 10},
+		{ID: "autologin", Severity: "high", Status: "unknown", Score: 5, Weight: 10},
+	}}
+
+	cases := map[string]string{
+		"":         "osupdate,sip,firewall,autologin",
+		"id":       "autologin,firewall,osupdate,sip",
+		"severity": "sip,osupdate,autologin,firewall",
+		"status":   "firewall,osupdate,autologin,sip",
+		"score":    "osupdate,firewall,autologin,sip",
+	}
+	for key, want := range cases {
+		sorted, err := Sort(rep, key)
+		if err != nil {
+			t.Fatalf("Sort(%q) error: %v", key, err)
+		}
+		var ids []string
+		for _, c := range sorted.Checks {
+			ids = append(ids, c.ID)
+		}
+		if got := strings.Join(ids, ","); got != want {
+			t.Errorf("Sort(%q) = %s, want %s", key, got, want)
+		}
+	}
+	if rep.Checks[0].ID != "osupdate" {
+		t.Fatal("Sort should not modify the original report")
+	}
+
+	if _, err := Sort(rep, "title"); err == nil {
+		t.Fatal("unknown sort key should be rejected")
+	}
+}
+
+func TestSort_ScorePutsUnscoredChecksLast(t *testing.T) {
+	// 採点対象外の結果は Score 0 のままだが、失点として扱わない
+	rep := types.Report{Checks: []types.CheckResult{
+		{ID: "filevault", Status: "waived", Score: 0, Weight: 20},
+		{ID: "osupdate", Status: "not_applicable", Score: 0, Weight: 20},
+		{ID: "sip", Status: "pass", Score: 20, Weight: 20},
+		{ID: "firewall", Status: "fail", Score: 0, Weight: 10},
+		{ID: "autologin", Status: "warn", Score: 5, Weight: 10},
+	}}
+	sorted, err := Sort(rep, "score")
+	if err != nil {
+		t.Fatalf("Sort error: %v", err)
+	}
+	var ids []string
+	for _, c := range sorted.Checks {
+		ids = append(ids, c.ID)
+	}
+	if got, want := strings.Join(ids, ","), "firewall,autologin,sip,filevault,osupdate"; got != want {
+		t.Errorf("Sort(score) = %s, want %s", got, want)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"sort"
+	"strings"
 
 	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/samuraidays/macinsight/pkg/types"
@@ -13,14 +14,15 @@ import (
 func WriteTable(w io.Writer, r types.Report) error {
 	t := table.NewWriter()
 	t.SetOutputMirror(w)
-	t.AppendHeader(table.Row{"Check", "Status", "Score", "Evidence"})
+	t.AppendHeader(table.Row{"Check", "Severity", "Status", "Score", "Evidence"})
 
-	// 見やすさのためタイトルでソート
-	checks := append([]types.CheckResult(nil), r.Checks...)
-	sort.Slice(checks, func(i, j int) bool { return checks[i].Title < checks[j].Title })
-
-	for _, c := range checks {
+	// レポートの順序のまま（並べ替えは Sort で行う）
+	for _, c := range r.Checks {
 		ev := ""
+		// 評価しなかった理由（skipped / cancelled など）を先頭に出す
+		if c.Reason != "" {
+			ev += fmt.Sprintf("reason=%s ", c.Reason)
+		}
 		// Evidenceを "k=v " で簡易整形
 		keys := make([]string, 0, len(c.Evidence))
 		for k := range c.Evidence {
@@ -30,10 +32,33 @@ func WriteTable(w io.Writer, r types.Report) error {
 		for _, k := range keys {
 			ev += fmt.Sprintf("%s=%s ", k, c.Evidence[k])
 		}
-		t.AppendRow(table.Row{c.Title, c.Status, c.Score, ev})
+		t.AppendRow(table.Row{c.Title, c.Severity, c.Status, fmt.Sprintf("%d/%d", c.Score, c.Weight), ev})
 	}
 
-	t.AppendFooter(table.Row{"TOTAL", "", r.Score, ""})
+	// 割合スコアと獲得点/配点、評価
+	t.AppendFooter(table.Row{"TOTAL", "", "", fmt.Sprintf("%d (%d/%d)", r.Score, r.EarnedScore, r.MaxScore), "GRADE " + r.Grade})
 	t.Render()
+
+	// カテゴリ別のサブスコア
+	if len(r.Categories) > 0 {
+		st := table.NewWriter()
+		st.SetOutputMirror(w)
+		st.AppendHeader(table.Row{"Category", "Score"})
+		for _, c := range r.Categories {
+			st.AppendRow(table.Row{c.Category, fmt.Sprintf("%d (%d/%d)", c.Score, c.EarnedScore, c.MaxScore)})
+		}
+		st.Render()
+	}
+
+	// フレームワーク別の合格率
+	if len(r.Compliance) > 0 {
+		ct := table.NewWriter()
+		ct.SetOutputMirror(w)
+		ct.AppendHeader(table.Row{"Framework", "Passed", "Pass Rate", "Controls"})
+		for _, c := range r.Compliance {
+			ct.AppendRow(table.Row{c.Framework, fmt.Sprintf("%d/%d", c.Passed, c.Total), fmt.Sprintf("%d%%", c.PassRate), strings.Join(c.Controls, ", ")})
+		}
+		ct.Render()
+	}
 	return nil
 }
@@ -41,3 +41,52 @@ func TestWriteTable_RendersRowsAndTotal(t *testing.T) {
 		}
 	}
 }
+
+func TestWriteTable_RendersCompliance(t *testing.T) {
+	rep := types.Report{
+		Version: "vtest",
+		Host:    types.HostInfo{Hostname: "host"},
+		Checks:  []types.CheckResult{{ID: "a", Title: "AAA", Status: "pass"}},
+		Compliance: []types.ComplianceSummary{
+			{Framework: "NIST SP 800-53 Rev. 5", Controls: []string{"SC-28", "SI-2"}, Passed: 1, Total: 2, PassRate: 50},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteTable(&buf, rep); err != nil {
+		t.Fatalf("WriteTable error: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{"NIST SP 800-53 Rev. 5", "1/2", "50%", "SC-28, SI-2"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("missing %q in output: %s", want, out)
+		}
+	}
+}
+
+func TestWriteTable_RendersSeverityGradeAndCategories(t *testing.T) {
+	rep := types.Report{
+		Version: "vtest",
+		Host:    types.HostInfo{Hostname: "host"},
+		Score:   50,
+		Grade:   "F",
+		Checks: []types.CheckResult{
+			{ID: "a", Title: "AAA", Status: "fail", Severity: "critical", Category: "encryption", Weight: 20},
+		},
+		Categories: []types.CategoryScore{
+			{Category: "encryption", Score: 0, MaxScore: 20},
+			{Category: "network", Score: 100, MaxScore: 20, EarnedScore: 20},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteTable(&buf, rep); err != nil {
+		t.Fatalf("WriteTable error: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{"critical", "GRADE F", "encryption", "0 (0/20)", "100 (20/20)"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("missing %q in output: %s", want, out)
+		}
+	}
+}
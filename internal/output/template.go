@@ -0,0 +1,166 @@
+package output
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/samuraidays/macinsight/pkg/types"
+)
+
+// ステータスごとの色名（statusColor / colorStatus）
+var statusColors = map[string]string{
+	"pass":           "green",
+	"fail":           "red",
+	"warn":           "yellow",
+	"unknown":        "magenta",
+	"cancelled":      "magenta",
+	"skipped":        "gray",
+	"waived":         "cyan",
+	"not_applicable": "gray",
+}
+
+// 色名ごとの ANSI エスケープシーケンス
+var ansiColors = map[string]string{
+	"red":     "\x1b[31m",
+	"green":   "\x1b[32m",
+	"yellow":  "\x1b[33m",
+	"blue":    "\x1b[34m",
+	"magenta": "\x1b[35m",
+	"cyan":    "\x1b[36m",
+	"gray":    "\x1b[90m",
+}
+
+// TemplateFuncs は --template で使えるヘルパー関数
+// チェック一覧を受け取る関数は最後の引数に取り、パイプラインでつなげられる（例: {{range .Checks | withStatus "fail,warn" | sortChecks "severity"}}）
+func TemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		// ステータスの色
+		"statusColor": func(status string) string {
+			if c, ok := statusColors[status]; ok {
+				return c
+			}
+			return "gray"
+		},
+		"colorize":    colorize,
+		"colorStatus": func(status string) string { return colorize(statusColors[status], status) },
+
+		// 並べ替え・絞り込み
+		"sortChecks": func(key string, list []types.CheckResult) ([]types.CheckResult, error) {
+			r, err := Sort(types.Report{Checks: list}, key)
+			return r.Checks, err
+		},
+		"withStatus": func(statuses string, list []types.CheckResult) []types.CheckResult {
+			return filterStatus(list, statuses, true)
+		},
+		"withoutStatus": func(statuses string, list []types.CheckResult) []types.CheckResult {
+			return filterStatus(list, statuses, false)
+		},
+		"countStatus": func(statuses string, list []types.CheckResult) int { return len(filterStatus(list, statuses, true)) },
+
+		// 証跡（無いキーは空文字）
+		"evidence":     func(key string, c types.CheckResult) string { return c.Evidence[key] },
+		"evidenceKeys": evidenceKeys,
+
+		// エスケープ
+		"toJSON": toJSON,
+		"csv":    csvRecord,
+
+		// 書式
+		"osLabel":   osLabel,
+		"timestamp": timestamp,
+		"join":      strings.Join,
+		"upper":     strings.ToUpper,
+		"lower":     strings.ToLower,
+	}
+}
+
+// ParseTemplate はテンプレートファイルを読み込む（text/template、ヘルパー関数付き）
+func ParseTemplate(path string) (*template.Template, error) {
+	t, err := template.New(filepath.Base(path)).Funcs(TemplateFuncs()).Option("missingkey=error").ParseFiles(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template %s: %w", path, err)
+	}
+	return t, nil
+}
+
+// レポートをユーザー定義のテンプレートで出力
+// 途中で失敗した場合に中途半端な出力を残さないよう、実行が終わってから書き込む
+func WriteTemplate(w io.Writer, t *template.Template, r types.Report) error {
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, r); err != nil {
+		return fmt.Errorf("failed to render template %s: %w", t.Name(), err)
+	}
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// 色名（statusColors / ansiColors のキー）で text を囲む、未知の色ならそのまま
+func colorize(color, text string) string {
+	code, ok := ansiColors[color]
+	if !ok {
+		return text
+	}
+	return code + text + "\x1b[0m"
+}
+
+// statuses はカンマ区切り（例: "fail,warn"）、keep=false なら該当するものを除く
+func filterStatus(list []types.CheckResult, statuses string, keep bool) []types.CheckResult {
+	want := map[string]bool{}
+	for _, s := range strings.Split(statuses, ",") {
+		want[strings.TrimSpace(s)] = true
+	}
+	out := []types.CheckResult{}
+	for _, c := range list {
+		if want[c.Status] == keep {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// 証跡のキーをアルファベット順で返す
+func evidenceKeys(c types.CheckResult) []string {
+	keys := make([]string, 0, len(c.Evidence))
+	for k := range c.Evidence {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// 値を1行の JSON にする（文字列なら引用符付きでエスケープされる）
+func toJSON(v interface{}) (string, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	// 証跡の "<" や "&" を \u003c などにしない
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// 値を1行の CSV レコードにする（改行は付けない）
+func csvRecord(fields ...interface{}) (string, error) {
+	rec := make([]string, len(fields))
+	for i, f := range fields {
+		rec[i] = fmt.Sprint(f)
+	}
+	var buf bytes.Buffer
+	cw := csv.NewWriter(&buf)
+	if err := cw.Write(rec); err != nil {
+		return "", err
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
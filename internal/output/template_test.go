@@ -0,0 +1,117 @@
+package output
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/samuraidays/macinsight/pkg/types"
+)
+
+var templateReport = types.Report{
+	Host:  types.HostInfo{Hostname: "mac-01", OS: types.OSInfo{Product: "macOS", Version: "15.6.1", Build: "24G90"}},
+	Score: 50,
+	Checks: []types.CheckResult{
+		{ID: "sip", Title: "SIP", Status: "pass", Severity: "critical", Score: 20, Weight: 20},
+		{ID: "autologin", Title: "Auto login", Status: "warn", Severity: "high", Score: 10, Weight: 20},
+		{ID: "filevault", Title: "FileVault, \"disk\"", Status: "fail", Severity: "critical", Weight: 20,
+			Evidence: map[string]string{"fdesetup": "FileVault is Off.", "note": "a <b> & c"}},
+	},
+}
+
+func renderTemplate(t *testing.T, src string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "report.tmpl")
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tmpl, err := ParseTemplate(path)
+	if err != nil {
+		t.Fatalf("ParseTemplate error: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := WriteTemplate(&buf, tmpl, templateReport); err != nil {
+		t.Fatalf("WriteTemplate error: %v", err)
+	}
+	return buf.String()
+}
+
+func TestWriteTemplate_Helpers(t *testing.T) {
+	cases := []struct {
+		name, src, want string
+	}{
+		{"fields", `{{.Host.Hostname}} {{osLabel .Host.OS}} {{.Score}}`, "mac-01 macOS 15.6.1 (24G90) 50"},
+		{"withStatus", `{{range .Checks | withStatus "fail,warn"}}{{.ID}} {{end}}`, "autologin filevault "},
+		{"withoutStatus", `{{range .Checks | withoutStatus "pass"}}{{.ID}} {{end}}`, "autologin filevault "},
+		{"countStatus", `{{countStatus "pass" .Checks}}/{{len .Checks}}`, "1/3"},
+		{"sortChecks", `{{range .Checks | sortChecks "status"}}{{.ID}} {{end}}`, "filevault autologin sip "},
+		{"filterThenSort", `{{range .Checks | withStatus "fail,pass" | sortChecks "id"}}{{.ID}} {{end}}`, "filevault sip "},
+		{"sortChecksScore", `{{range .Checks | sortChecks "score"}}{{.ID}} {{end}}`, "filevault autologin sip "},
+		{"evidence", `{{range .Checks}}[{{evidence "fdesetup" .}}]{{end}}`, "[][][FileVault is Off.]"},
+		{"evidenceKeys", `{{range .Checks}}{{join (evidenceKeys .) ","}}{{end}}`, "fdesetup,note"},
+		{"statusColor", `{{range .Checks}}{{statusColor .Status}} {{end}}`, "green yellow red "},
+		{"colorStatus", `{{colorStatus "fail"}}|{{colorize "nope" "x"}}`, "\x1b[31mfail\x1b[0m|x"},
+		{"toJSON", `{{range .Checks | withStatus "fail"}}{{toJSON .Evidence}} {{toJSON .Title}}{{end}}`,
+			`{"fdesetup":"FileVault is Off.","note":"a <b> & c"} "FileVault, \"disk\""`},
+		{"csv", `{{range .Checks | withStatus "fail"}}{{csv .ID .Title .Score}}{{end}}`, `filevault,"FileVault, ""disk""",0`},
+		{"upper", `{{upper .Host.Hostname}}`, "MAC-01"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := renderTemplate(t, tc.src); got != tc.want {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseTemplate_Errors(t *testing.T) {
+	if _, err := ParseTemplate(filepath.Join(t.TempDir(), "missing.tmpl")); err == nil {
+		t.Error("expected error for missing template file")
+	}
+
+	path := filepath.Join(t.TempDir(), "bad.tmpl")
+	_ = os.WriteFile(path, []byte(`{{range .Checks}}`), 0o644)
+	if _, err := ParseTemplate(path); err == nil || !strings.Contains(err.Error(), "bad.tmpl") {
+		t.Errorf("expected parse error naming the file, got %v", err)
+	}
+}
+
+func TestWriteTemplate_ExecutionErrorWritesNothing(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.tmpl")
+	// 途中まで出力できても、失敗したら何も書かない
+	_ = os.WriteFile(path, []byte(`{{.Host.Hostname}}{{range .Checks | sortChecks "title"}}{{end}}`), 0o644)
+	tmpl, err := ParseTemplate(path)
+	if err != nil {
+		t.Fatalf("ParseTemplate error: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := WriteTemplate(&buf, tmpl, templateReport); err == nil || !strings.Contains(err.Error(), "unknown sort key") {
+		t.Errorf("expected unknown sort key error, got %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("partial output written: %q", buf.String())
+	}
+}
+
+func TestExampleTemplates(t *testing.T) {
+	paths, _ := filepath.Glob("../../examples/templates/*.tmpl")
+	if len(paths) == 0 {
+		t.Fatal("no example templates found")
+	}
+	for _, p := range paths {
+		tmpl, err := ParseTemplate(p)
+		if err != nil {
+			t.Fatalf("ParseTemplate(%s) error: %v", p, err)
+		}
+		var buf bytes.Buffer
+		if err := WriteTemplate(&buf, tmpl, templateReport); err != nil {
+			t.Errorf("WriteTemplate(%s) error: %v", p, err)
+		}
+		if !strings.Contains(buf.String(), "mac-01") {
+			t.Errorf("%s: hostname missing in output:\n%s", p, buf.String())
+		}
+	}
+}
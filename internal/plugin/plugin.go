@@ -0,0 +1,231 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/samuraidays/macinsight/internal/checks"
+	"github.com/samuraidays/macinsight/internal/executil"
+	"github.com/samuraidays/macinsight/pkg/types"
+)
+
+// プロトコルの定数
+const (
+	ProtocolVersion = 1
+	PathPrefix      = "macinsight-check-" // PATH 上で探すプラグインの名前の接頭辞
+
+	describeTimeout = 5 * time.Second
+	runTimeout      = 30 * time.Second // runner 以外から呼ばれ、チェック別タイムアウトが無い場合の上限
+)
+
+// Metadata は --describe の応答
+type Metadata struct {
+	ID           string          `json:"id"`
+	Title        string          `json:"title"`
+	Weight       int             `json:"weight"`
+	Category     string          `json:"category"`
+	Severity     string          `json:"severity,omitempty"` // 省略時 medium
+	Description  string          `json:"description"`
+	Platforms    []string        `json:"platforms"`
+	RequiresRoot bool            `json:"requires_root,omitempty"` // root 権限が無ければ skipped
+	Controls     []types.Control `json:"controls,omitempty"`
+}
+
+// Request は --run 時に標準入力へ渡す JSON
+type Request struct {
+	Protocol int               `json:"protocol"`
+	ID       string            `json:"id"`
+	Params   map[string]string `json:"params,omitempty"`
+}
+
+// Plugin は外部実行ファイルによるチェック
+type Plugin struct {
+	Path string
+	Meta Metadata
+}
+
+// Discover はディレクトリ内の実行ファイルと、searchPath 指定時は PATH 上の macinsight-check-* を返す
+// 同名のものは先に見つかった方を優先する
+func Discover(dirs []string, searchPath bool) ([]string, error) {
+	var found []string
+	seen := map[string]bool{}
+	add := func(path string) {
+		name := filepath.Base(path)
+		if !seen[name] {
+			seen[name] = true
+			found = append(found, path)
+		}
+	}
+
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read plugin dir %s: %w", dir, err)
+		}
+		for _, e := range entries {
+			path := filepath.Join(dir, e.Name())
+			if isExecutable(path) {
+				add(path)
+			}
+		}
+	}
+
+	if searchPath {
+		for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+			matches, _ := filepath.Glob(filepath.Join(dir, PathPrefix+"*"))
+			sort.Strings(matches)
+			for _, path := range matches {
+				if isExecutable(path) {
+					add(path)
+				}
+			}
+		}
+	}
+	return found, nil
+}
+
+func isExecutable(path string) bool {
+	fi, err := os.Stat(path)
+	if err != nil || fi.IsDir() {
+		return false
+	}
+	return fi.Mode().Perm()&0o111 != 0
+}
+
+// Load は --describe でメタデータを取得してプラグインを作る
+func Load(ctx context.Context, path string) (*Plugin, error) {
+	res := executil.Run(ctx, describeTimeout, path, "--describe")
+	if res.Err != nil {
+		return nil, fmt.Errorf("plugin %s: --describe failed: %v", path, res.Err)
+	}
+
+	var m Metadata
+	if err := json.Unmarshal([]byte(res.Stdout), &m); err != nil {
+		return nil, fmt.Errorf("plugin %s: invalid --describe output: %w", path, err)
+	}
+	if m.ID == "" || m.Title == "" {
+		return nil, fmt.Errorf("plugin %s: --describe must return id and title", path)
+	}
+	if !checks.IDPattern.MatchString(m.ID) {
+		return nil, fmt.Errorf("plugin %s: id %q must match %s", path, m.ID, checks.IDPattern)
+	}
+	if m.Weight < 0 {
+		return nil, fmt.Errorf("plugin %s: weight must not be negative", path)
+	}
+	if m.Category == "" {
+		m.Category = "custom"
+	}
+	if m.Severity == "" {
+		m.Severity = checks.SeverityMedium
+	}
+	if !checks.IsSeverity(m.Severity) {
+		return nil, fmt.Errorf("plugin %s: invalid severity %q (want one of %v)", path, m.Severity, checks.Severities)
+	}
+	if len(m.Platforms) == 0 {
+		m.Platforms = []string{"darwin"}
+	}
+	return &Plugin{Path: path, Meta: m}, nil
+}
+
+// Check はプラグインを checks.Check として返す
+func (p *Plugin) Check() checks.Check {
+	return checks.New(checks.Definition{
+		ID:           p.Meta.ID,
+		Title:        p.Meta.Title,
+		Weight:       p.Meta.Weight,
+		Category:     p.Meta.Category,
+		Severity:     p.Meta.Severity,
+		Description:  p.Meta.Description,
+		Platforms:    p.Meta.Platforms,
+		Controls:     p.Meta.Controls,
+		RequiresRoot: p.Meta.RequiresRoot,
+		Commands:     [][]string{{p.Path, "--run"}},
+	}, p.Run)
+}
+
+// Run はプラグインを --run で実行し、標準出力の CheckResult を検証して返す
+func (p *Plugin) Run(ctx context.Context) types.CheckResult {
+	req := Request{Protocol: ProtocolVersion, ID: p.Meta.ID, Params: checks.Params(ctx)}
+	in, _ := json.Marshal(req)
+
+	// runner のチェック別タイムアウトと揃え、タイムアウト時のメッセージに実際の時間を出す
+	timeout := runTimeout
+	if d, ok := checks.Timeout(ctx); ok {
+		timeout = d
+	}
+	res := executil.RunCommand(ctx, executil.Command{
+		Name:    p.Path,
+		Args:    []string{"--run"},
+		Timeout: timeout,
+		Stdin:   string(in),
+	})
+
+	if res.Err != nil {
+		cr := p.unknown(fmt.Sprintf("plugin failed: %v", res.Err), res.Stderr)
+		cr.Error = checks.ErrorOf(res.Err)
+		return cr
+	}
+
+	cr, err := p.decode(res.Stdout)
+	if err != nil {
+		cr := p.unknown(err.Error(), res.Stderr)
+		cr.Error = &types.CheckError{Kind: string(executil.KindOther), Message: err.Error()}
+		return cr
+	}
+	return cr
+}
+
+// 応答の CheckResult を検証する
+func (p *Plugin) decode(out string) (types.CheckResult, error) {
+	var cr types.CheckResult
+	if err := json.Unmarshal([]byte(out), &cr); err != nil {
+		return cr, fmt.Errorf("invalid plugin output: %w", err)
+	}
+	if cr.ID == "" {
+		cr.ID = p.Meta.ID
+	}
+	if cr.ID != p.Meta.ID {
+		return cr, fmt.Errorf("plugin returned id %q, want %q", cr.ID, p.Meta.ID)
+	}
+	if cr.Title == "" {
+		cr.Title = p.Meta.Title
+	}
+	switch cr.Status {
+	case "pass", "fail", "warn", "unknown":
+	default:
+		return cr, fmt.Errorf("plugin returned invalid status %q", cr.Status)
+	}
+	if cr.Error != nil && !isErrorKind(cr.Error.Kind) {
+		return cr, fmt.Errorf("plugin returned invalid error kind %q", cr.Error.Kind)
+	}
+	return cr, nil
+}
+
+func isErrorKind(kind string) bool {
+	for _, k := range executil.ErrorKinds {
+		if string(k) == kind {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Plugin) unknown(msg, stderr string) types.CheckResult {
+	ev := map[string]string{"plugin": p.Path, "error": msg}
+	if s := strings.TrimSpace(stderr); s != "" {
+		ev["stderr"] = s
+	}
+	return types.CheckResult{
+		ID:             p.Meta.ID,
+		Title:          p.Meta.Title,
+		Status:         "unknown",
+		Evidence:       ev,
+		Recommendation: "プラグインの実行可否と出力形式を確認",
+	}
+}
@@ -0,0 +1,134 @@
+package plugin
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/samuraidays/macinsight/internal/checks"
+)
+
+// テスト用のシェルスクリプトプラグインを作る
+func writePlugin(t *testing.T, dir, name, runOutput string) string {
+	t.Helper()
+	script := `#!/bin/sh
+if [ "$1" = "--describe" ]; then
+  echo '{"id":"org.sample","title":"Sample plugin","weight":5,"category":"network"}'
+  exit 0
+fi
+cat > /dev/null
+echo '` + runOutput + `'
+`
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+		t.Fatalf("write plugin: %v", err)
+	}
+	return path
+}
+
+func TestDiscover_DirAndPath(t *testing.T) {
+	dir := t.TempDir()
+	writePlugin(t, dir, "sample", `{}`)
+	if err := os.WriteFile(filepath.Join(dir, "README"), []byte("not executable"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	pathDir := t.TempDir()
+	writePlugin(t, pathDir, PathPrefix+"other", `{}`)
+	writePlugin(t, pathDir, "unrelated", `{}`)
+	t.Setenv("PATH", pathDir)
+
+	found, err := Discover([]string{dir}, false)
+	if err != nil || len(found) != 1 {
+		t.Fatalf("expected 1 plugin from dir, got %v (err=%v)", found, err)
+	}
+
+	found, err = Discover([]string{dir}, true)
+	if err != nil || len(found) != 2 {
+		t.Fatalf("expected 2 plugins with PATH search, got %v (err=%v)", found, err)
+	}
+}
+
+func TestPlugin_DescribeAndRun(t *testing.T) {
+	path := writePlugin(t, t.TempDir(), "sample", `{"status":"fail","evidence":{"k":"v"},"recommendation":"fix it"}`)
+
+	p, err := Load(context.Background(), path)
+	if err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+	c := p.Check()
+	if c.ID() != "org.sample" || c.Weight() != 5 || c.Category() != "network" {
+		t.Fatalf("unexpected metadata: id=%s weight=%d category=%s", c.ID(), c.Weight(), c.Category())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	cr := c.Run(checks.WithParams(ctx, map[string]string{"x": "1"}))
+	if cr.ID != "org.sample" || cr.Title != "Sample plugin" || cr.Status != "fail" || cr.Evidence["k"] != "v" {
+		t.Fatalf("unexpected result: %+v", cr)
+	}
+}
+
+func TestPlugin_InvalidOutputBecomesUnknown(t *testing.T) {
+	for name, out := range map[string]string{
+		"bad_json":   `not json`,
+		"bad_status": `{"status":"great"}`,
+		"wrong_id":   `{"id":"other","status":"pass"}`,
+		"bad_kind":   `{"status":"unknown","error":{"kind":"oops","message":"x"}}`,
+	} {
+		path := writePlugin(t, t.TempDir(), name, out)
+		p, err := Load(context.Background(), path)
+		if err != nil {
+			t.Fatalf("Load error: %v", err)
+		}
+		cr := p.Run(context.Background())
+		if cr.Status != "unknown" || cr.Evidence["error"] == "" {
+			t.Errorf("%s: expected unknown with error evidence, got %+v", name, cr)
+		}
+		if cr.Error == nil || cr.Error.Kind != "error" {
+			t.Errorf("%s: expected error kind \"error\", got %+v", name, cr.Error)
+		}
+	}
+}
+
+// --describe の応答と --run の動作を指定してスクリプトプラグインを作る
+func writeScript(t *testing.T, describe, run string) string {
+	t.Helper()
+	script := "#!/bin/sh\nif [ \"$1\" = \"--describe\" ]; then\n  echo '" + describe + "'\n  exit 0\nfi\n" + run + "\n"
+	path := filepath.Join(t.TempDir(), "plugin")
+	if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+		t.Fatalf("write plugin: %v", err)
+	}
+	return path
+}
+
+func TestLoad_RejectsInvalidID(t *testing.T) {
+	for _, id := range []string{"Org.Sample", "has space", "-dash", "a/b"} {
+		path := writeScript(t, `{"id":"`+id+`","title":"Bad"}`, "exit 0")
+		if _, err := Load(context.Background(), path); err == nil || !strings.Contains(err.Error(), "must match") {
+			t.Errorf("id %q should be rejected, got %v", id, err)
+		}
+	}
+}
+
+func TestPlugin_TimeoutReportsPerCheckTimeout(t *testing.T) {
+	path := writeScript(t, `{"id":"org.slow","title":"Slow plugin"}`, "sleep 5")
+	p, err := Load(context.Background(), path)
+	if err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+
+	// runner と同じく、チェック別タイムアウトで打ち切る context を渡す
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	cr := p.Run(checks.WithTimeout(ctx, 200*time.Millisecond))
+	if cr.Status != "unknown" || cr.Error == nil || cr.Error.Kind != "timeout" {
+		t.Fatalf("expected unknown with timeout error, got %+v", cr)
+	}
+	if !strings.Contains(cr.Error.Message, "timed out after 200ms") {
+		t.Errorf("timeout message should report the per-check timeout, got %q", cr.Error.Message)
+	}
+}
@@ -0,0 +1,189 @@
+package profile
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/samuraidays/macinsight/internal/checks"
+	"github.com/samuraidays/macinsight/internal/config"
+)
+
+// 組み込みプロファイル
+//
+//go:embed profiles/*.yaml
+var builtinFS embed.FS
+
+// Profile は実行するチェックと配点・重要度・期待値（パラメータ）の組
+type Profile struct {
+	Name        string  `yaml:"name"`
+	Title       string  `yaml:"title"`
+	Description string  `yaml:"description,omitempty"`
+	Checks      []Entry `yaml:"checks"`
+
+	Source string `yaml:"-"` // "builtin" またはファイルパス
+}
+
+// Entry はプロファイル内のチェック1件
+type Entry struct {
+	ID       string            `yaml:"id"`
+	Weight   *int              `yaml:"weight,omitempty"`   // 省略時はチェックの既定配点
+	Severity string            `yaml:"severity,omitempty"` // critical | high | medium | low | info
+	Params   map[string]string `yaml:"params,omitempty"`   // 期待値などのチェック別パラメータ
+}
+
+// UserDir はユーザプロファイルの置き場所（<設定ディレクトリ>/profiles）
+func UserDir() string {
+	dir := config.Dir()
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "profiles")
+}
+
+// All は組み込みとユーザプロファイルを名前順で返す（同名はユーザ側を優先）
+func All() ([]*Profile, error) {
+	byName := map[string]*Profile{}
+
+	builtins, err := fs.Glob(builtinFS, "profiles/*.yaml")
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range builtins {
+		data, err := builtinFS.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		p, err := parse(data, "builtin")
+		if err != nil {
+			return nil, fmt.Errorf("builtin profile %s: %w", name, err)
+		}
+		byName[p.Name] = p
+	}
+
+	if dir := UserDir(); dir != "" {
+		files, err := filepath.Glob(filepath.Join(dir, "*.y*ml"))
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range files {
+			p, err := LoadFile(path)
+			if err != nil {
+				return nil, err
+			}
+			byName[p.Name] = p
+		}
+	}
+
+	list := make([]*Profile, 0, len(byName))
+	for _, p := range byName {
+		list = append(list, p)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list, nil
+}
+
+// Get は名前またはファイルパスでプロファイルを返す
+func Get(nameOrPath string) (*Profile, error) {
+	if strings.ContainsRune(nameOrPath, os.PathSeparator) || strings.HasSuffix(nameOrPath, ".yaml") || strings.HasSuffix(nameOrPath, ".yml") {
+		return LoadFile(nameOrPath)
+	}
+
+	all, err := All()
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range all {
+		if p.Name == nameOrPath {
+			return p, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown profile %q (see: macinsight profiles list)", nameOrPath)
+}
+
+// LoadFile はプロファイルファイルを読み込む
+func LoadFile(path string) (*Profile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read profile %s: %w", path, err)
+	}
+	p, err := parse(data, path)
+	if err != nil {
+		return nil, fmt.Errorf("profile %s: %w", path, err)
+	}
+	return p, nil
+}
+
+func parse(data []byte, source string) (*Profile, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	p := &Profile{}
+	if err := dec.Decode(p); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	p.Source = source
+
+	if p.Name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	if len(p.Checks) == 0 {
+		return nil, fmt.Errorf("at least one check is required")
+	}
+	seen := map[string]bool{}
+	for _, e := range p.Checks {
+		if e.ID == "" {
+			return nil, fmt.Errorf("check id is required")
+		}
+		if seen[e.ID] {
+			return nil, fmt.Errorf("check %s is listed twice", e.ID)
+		}
+		seen[e.ID] = true
+		if e.Weight != nil && *e.Weight < 0 {
+			return nil, fmt.Errorf("weight for %s must not be negative", e.ID)
+		}
+		if e.Severity != "" && !checks.IsSeverity(e.Severity) {
+			return nil, fmt.Errorf("invalid severity %q for %s (want one of %v)", e.Severity, e.ID, checks.Severities)
+		}
+	}
+	return p, nil
+}
+
+// Validate はプロファイルのチェックがすべて登録済みか確認する
+// ルール/プラグインを登録した後に呼ぶこと
+func (p *Profile) Validate() error {
+	for _, e := range p.Checks {
+		if _, ok := checks.Lookup(e.ID); !ok {
+			return fmt.Errorf("profile %s: unknown check %q", p.Name, e.ID)
+		}
+	}
+	return nil
+}
+
+// Entry は ID のエントリを返す
+func (p *Profile) Entry(id string) (Entry, bool) {
+	for _, e := range p.Checks {
+		if e.ID == id {
+			return e, true
+		}
+	}
+	return Entry{}, false
+}
+
+// Write はプロファイルを YAML で出力する
+func (p *Profile) Write(w io.Writer) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(p); err != nil {
+		return err
+	}
+	return enc.Close()
+}
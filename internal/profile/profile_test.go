@@ -0,0 +1,78 @@
+package profile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAll_BuiltinProfilesAreValid(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	all, err := All()
+	if err != nil {
+		t.Fatalf("All error: %v", err)
+	}
+	names := map[string]bool{}
+	for _, p := range all {
+		names[p.Name] = true
+		if err := p.Validate(); err != nil {
+			t.Errorf("builtin profile %s: %v", p.Name, err)
+		}
+	}
+	for _, want := range []string{"baseline", "cis-l1", "cis-l2"} {
+		if !names[want] {
+			t.Errorf("missing builtin profile %s", want)
+		}
+	}
+}
+
+func TestAll_UserProfileOverridesBuiltin(t *testing.T) {
+	cfgHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", cfgHome)
+	dir := filepath.Join(cfgHome, "macinsight", "profiles")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	body := "name: cis-l1\ntitle: Our CIS L1\nchecks:\n  - id: sip\n    weight: 50\n"
+	if err := os.WriteFile(filepath.Join(dir, "cis.yaml"), []byte(body), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := Get("cis-l1")
+	if err != nil {
+		t.Fatalf("Get error: %v", err)
+	}
+	if p.Title != "Our CIS L1" || len(p.Checks) != 1 || *p.Checks[0].Weight != 50 {
+		t.Fatalf("user profile should override builtin, got %+v", p)
+	}
+}
+
+func TestGet_ByPathAndValidation(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "custom.yaml")
+	if err := os.WriteFile(path, []byte("name: custom\nchecks:\n  - id: nosuchcheck\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	p, err := Get(path)
+	if err != nil {
+		t.Fatalf("Get by path error: %v", err)
+	}
+	if err := p.Validate(); err == nil {
+		t.Fatal("unknown check IDs should fail validation")
+	}
+
+	bad := filepath.Join(dir, "bad.yaml")
+	if err := os.WriteFile(bad, []byte("name: bad\nchecks:\n  - id: sip\n    severity: urgent\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Get(bad); err == nil {
+		t.Fatal("invalid severity should be rejected")
+	}
+
+	if _, err := Get("nosuchprofile"); err == nil {
+		t.Fatal("unknown profile name should fail")
+	}
+}
@@ -0,0 +1,192 @@
+package rules
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// value はコマンド出力から判定対象の値を取り出す（見つからなければ found=false）
+func (e Extract) value(out string) (string, bool) {
+	switch e.Type {
+	case "regex":
+		m := e.re.FindStringSubmatch(out)
+		if m == nil {
+			return "", false
+		}
+		if len(m) > 1 {
+			return m[1], true
+		}
+		return m[0], true
+	case "line":
+		return lineValue(out, e.Key)
+	case "plist":
+		v, err := parsePlist(out)
+		if err != nil {
+			return "", false
+		}
+		return lookup(v, e.Key)
+	case "json":
+		var v interface{}
+		if err := json.Unmarshal([]byte(out), &v); err != nil {
+			return "", false
+		}
+		return lookup(v, strings.TrimPrefix(strings.TrimPrefix(e.Key, "$"), "."))
+	default:
+		return strings.TrimSpace(out), true
+	}
+}
+
+// "Key: value" / "Key = value" 形式の行から値を取り出す
+func lineValue(out, key string) (string, bool) {
+	for _, l := range strings.Split(out, "\n") {
+		lt := strings.TrimSpace(l)
+		if !strings.HasPrefix(lt, key) {
+			continue
+		}
+		rest := strings.TrimSpace(strings.TrimPrefix(lt, key))
+		rest = strings.TrimSpace(strings.TrimLeft(rest, ":="))
+		return rest, true
+	}
+	return "", false
+}
+
+// ドット区切りのパスで map / 配列をたどる
+func lookup(v interface{}, path string) (string, bool) {
+	if path != "" {
+		for _, p := range strings.Split(path, ".") {
+			switch node := v.(type) {
+			case map[string]interface{}:
+				next, ok := node[p]
+				if !ok {
+					return "", false
+				}
+				v = next
+			case []interface{}:
+				i, err := strconv.Atoi(p)
+				if err != nil || i < 0 || i >= len(node) {
+					return "", false
+				}
+				v = node[i]
+			default:
+				return "", false
+			}
+		}
+	}
+	return scalar(v)
+}
+
+func scalar(v interface{}) (string, bool) {
+	switch x := v.(type) {
+	case nil:
+		return "", false
+	case string:
+		return x, true
+	case bool:
+		return strconv.FormatBool(x), true
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64), true
+	case int64:
+		return strconv.FormatInt(x, 10), true
+	default:
+		// map / 配列は JSON 文字列として返す
+		b, err := json.Marshal(x)
+		if err != nil {
+			return "", false
+		}
+		return string(b), true
+	}
+}
+
+// XML プロパティリスト（defaults export / plutil -convert xml1 の出力）を汎用値に変換する
+func parsePlist(out string) (interface{}, error) {
+	dec := xml.NewDecoder(strings.NewReader(out))
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, fmt.Errorf("no plist value: %w", err)
+		}
+		if se, ok := tok.(xml.StartElement); ok && se.Name.Local != "plist" {
+			return plistValue(dec, se)
+		}
+	}
+}
+
+func plistValue(dec *xml.Decoder, se xml.StartElement) (interface{}, error) {
+	switch se.Name.Local {
+	case "dict":
+		m := map[string]interface{}{}
+		var key string
+		for {
+			tok, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			switch t := tok.(type) {
+			case xml.StartElement:
+				if t.Name.Local == "key" {
+					if err := dec.DecodeElement(&key, &t); err != nil {
+						return nil, err
+					}
+					continue
+				}
+				v, err := plistValue(dec, t)
+				if err != nil {
+					return nil, err
+				}
+				m[key] = v
+			case xml.EndElement:
+				return m, nil
+			}
+		}
+	case "array":
+		var list []interface{}
+		for {
+			tok, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			switch t := tok.(type) {
+			case xml.StartElement:
+				v, err := plistValue(dec, t)
+				if err != nil {
+					return nil, err
+				}
+				list = append(list, v)
+			case xml.EndElement:
+				return list, nil
+			}
+		}
+	case "true", "false":
+		if err := dec.Skip(); err != nil {
+			return nil, err
+		}
+		return se.Name.Local == "true", nil
+	case "integer":
+		var s string
+		if err := dec.DecodeElement(&s, &se); err != nil {
+			return nil, err
+		}
+		n, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		return n, nil
+	case "real":
+		var s string
+		if err := dec.DecodeElement(&s, &se); err != nil {
+			return nil, err
+		}
+		return strconv.ParseFloat(strings.TrimSpace(s), 64)
+	default:
+		// string / date / data はテキストとして扱う
+		var s string
+		if err := dec.DecodeElement(&s, &se); err != nil && err != io.EOF {
+			return nil, err
+		}
+		return s, nil
+	}
+}
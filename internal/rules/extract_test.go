@@ -0,0 +1,59 @@
+package rules
+
+import "testing"
+
+func TestExtract_Line(t *testing.T) {
+	e := Extract{Type: "line", Key: "Remote Login:"}
+	v, ok := e.value("Remote Login: Off\n")
+	if !ok || v != "Off" {
+		t.Fatalf("line extract mismatch: %q %v", v, ok)
+	}
+	if _, ok := e.value("something else\n"); ok {
+		t.Fatal("missing key should not be found")
+	}
+}
+
+func TestExtract_Plist(t *testing.T) {
+	out := `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>askForPassword</key>
+	<integer>1</integer>
+	<key>enabled</key>
+	<true/>
+	<key>modules</key>
+	<array>
+		<dict>
+			<key>name</key>
+			<string>Flurry</string>
+		</dict>
+	</array>
+</dict>
+</plist>`
+
+	cases := map[string]string{
+		"askForPassword": "1",
+		"enabled":        "true",
+		"modules.0.name": "Flurry",
+	}
+	for key, want := range cases {
+		v, ok := Extract{Type: "plist", Key: key}.value(out)
+		if !ok || v != want {
+			t.Errorf("plist %s: got %q %v, want %q", key, v, ok, want)
+		}
+	}
+	if _, ok := (Extract{Type: "plist", Key: "missing"}).value(out); ok {
+		t.Fatal("missing plist key should not be found")
+	}
+}
+
+func TestExtract_JSONPath(t *testing.T) {
+	out := `{"a":{"b":[{"c":"x"},{"c":false}]}}`
+	for key, want := range map[string]string{"a.b.0.c": "x", "$.a.b.1.c": "false"} {
+		v, ok := Extract{Type: "json", Key: key}.value(out)
+		if !ok || v != want {
+			t.Errorf("json %s: got %q %v, want %q", key, v, ok, want)
+		}
+	}
+}
@@ -0,0 +1,316 @@
+package rules
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/samuraidays/macinsight/internal/checks"
+	"github.com/samuraidays/macinsight/internal/executil"
+	"github.com/samuraidays/macinsight/pkg/types"
+)
+
+// ルールファイル1件（1ファイル1チェック）
+type Rule struct {
+	ID             string          `yaml:"id"`
+	Title          string          `yaml:"title"`
+	Description    string          `yaml:"description"`
+	Category       string          `yaml:"category"`
+	Severity       string          `yaml:"severity"` // 省略時 medium
+	Weight         int             `yaml:"weight"`
+	Platforms      []string        `yaml:"platforms"`
+	RequiresRoot   bool            `yaml:"requires_root"` // root 権限が無ければ skipped
+	Controls       []types.Control `yaml:"controls"`
+	Recommendation string          `yaml:"recommendation"`
+	Command        Command         `yaml:"command"`
+	Extract        Extract         `yaml:"extract"`
+	Pass           Condition       `yaml:"pass"`
+	Warn           Condition       `yaml:"warn"`
+	Fail           Condition       `yaml:"fail"`
+	Default        string          `yaml:"default"`  // どの条件にも一致しない場合のステータス（既定 fail）
+	OnError        string          `yaml:"on_error"` // コマンド失敗時の扱い（unknown | pass | warn | fail | evaluate、既定 unknown）
+}
+
+// 実行するコマンド
+type Command struct {
+	Name    string        `yaml:"name"`
+	Args    []string      `yaml:"args"`
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// 値の取り出し方
+type Extract struct {
+	Type    string `yaml:"type"`    // raw | regex | line | plist | json（既定 raw）
+	Pattern string `yaml:"pattern"` // regex: 最初のキャプチャグループ（無ければ一致全体）
+	Key     string `yaml:"key"`     // line: 行頭のキー / plist・json: ドット区切りのパス
+
+	re *regexp.Regexp
+}
+
+// 判定条件（指定したものはすべて満たす必要がある）
+type Condition struct {
+	Equals    *string  `yaml:"equals"`
+	NotEquals *string  `yaml:"not_equals"`
+	In        []string `yaml:"in"`
+	Contains  string   `yaml:"contains"`
+	Matches   string   `yaml:"matches"`
+	Exists    *bool    `yaml:"exists"`
+	Min       *float64 `yaml:"min"`
+	Max       *float64 `yaml:"max"`
+
+	re *regexp.Regexp
+}
+
+// LoadDir はディレクトリ内の *.yaml / *.yml / *.json をルールとして読み込む
+func LoadDir(dir string) ([]*Rule, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rules dir %s: %w", dir, err)
+	}
+
+	var files []string
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		switch strings.ToLower(filepath.Ext(e.Name())) {
+		case ".yaml", ".yml", ".json":
+			files = append(files, filepath.Join(dir, e.Name()))
+		}
+	}
+	sort.Strings(files)
+
+	rules := make([]*Rule, 0, len(files))
+	for _, f := range files {
+		r, err := LoadFile(f)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+// LoadFile はルールファイル1件を読み込んで検証する（JSON は YAML のサブセットとして読む）
+func LoadFile(path string) (*Rule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rule %s: %w", path, err)
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	r := &Rule{}
+	if err := dec.Decode(r); err != nil {
+		return nil, fmt.Errorf("failed to parse rule %s: %w", path, err)
+	}
+	if err := r.compile(); err != nil {
+		return nil, fmt.Errorf("invalid rule %s: %w", path, err)
+	}
+	return r, nil
+}
+
+// 必須項目の検証と正規表現のコンパイル
+func (r *Rule) compile() error {
+	if !checks.IDPattern.MatchString(r.ID) {
+		return fmt.Errorf("id %q must match %s", r.ID, checks.IDPattern)
+	}
+	if r.Title == "" {
+		return fmt.Errorf("title is required")
+	}
+	if r.Weight < 0 {
+		return fmt.Errorf("weight must not be negative")
+	}
+	if r.Command.Name == "" {
+		return fmt.Errorf("command.name is required")
+	}
+	if r.Command.Timeout <= 0 {
+		r.Command.Timeout = 3 * time.Second
+	}
+	if len(r.Platforms) == 0 {
+		r.Platforms = []string{"darwin"}
+	}
+	if r.Category == "" {
+		r.Category = "custom"
+	}
+	if r.Severity == "" {
+		r.Severity = checks.SeverityMedium
+	}
+	if !checks.IsSeverity(r.Severity) {
+		return fmt.Errorf("invalid severity %q (want one of %v)", r.Severity, checks.Severities)
+	}
+
+	switch r.Extract.Type {
+	case "":
+		r.Extract.Type = "raw"
+	case "raw":
+	case "regex":
+		re, err := regexp.Compile(r.Extract.Pattern)
+		if err != nil {
+			return fmt.Errorf("extract.pattern: %w", err)
+		}
+		r.Extract.re = re
+	case "line", "plist", "json":
+		if r.Extract.Key == "" {
+			return fmt.Errorf("extract.key is required for %s", r.Extract.Type)
+		}
+	default:
+		return fmt.Errorf("unknown extract.type %q", r.Extract.Type)
+	}
+
+	if r.Pass.empty() && r.Warn.empty() && r.Fail.empty() {
+		return fmt.Errorf("at least one of pass, warn, fail is required")
+	}
+	for name, c := range map[string]*Condition{"pass": &r.Pass, "warn": &r.Warn, "fail": &r.Fail} {
+		if c.Matches == "" {
+			continue
+		}
+		re, err := regexp.Compile(c.Matches)
+		if err != nil {
+			return fmt.Errorf("%s.matches: %w", name, err)
+		}
+		c.re = re
+	}
+
+	if r.Default == "" {
+		r.Default = "fail"
+	}
+	if !isStatus(r.Default) {
+		return fmt.Errorf("invalid default %q", r.Default)
+	}
+	switch r.OnError {
+	case "":
+		r.OnError = "unknown"
+	case "unknown", "pass", "warn", "fail", "evaluate":
+	default:
+		return fmt.Errorf("invalid on_error %q", r.OnError)
+	}
+	return nil
+}
+
+func isStatus(s string) bool {
+	return s == "pass" || s == "warn" || s == "fail"
+}
+
+// Check はルールを checks.Check として返す
+func (r *Rule) Check() checks.Check {
+	return checks.New(checks.Definition{
+		ID:           r.ID,
+		Title:        r.Title,
+		Weight:       r.Weight,
+		Category:     r.Category,
+		Severity:     r.Severity,
+		Description:  r.Description,
+		Platforms:    r.Platforms,
+		Controls:     r.Controls,
+		RequiresRoot: r.RequiresRoot,
+		Commands:     [][]string{append([]string{r.Command.Name}, r.Command.Args...)},
+	}, r.Run)
+}
+
+// Run はコマンドを実行して条件を評価する
+func (r *Rule) Run(ctx context.Context) types.CheckResult {
+	res := executil.Run(ctx, r.Command.Timeout, r.Command.Name, r.Command.Args...)
+
+	cr := types.CheckResult{
+		ID:       r.ID,
+		Title:    r.Title,
+		Evidence: map[string]string{"output": strings.TrimSpace(res.Stdout)},
+	}
+
+	cr.Error = checks.ErrorOf(res.Err)
+	if res.Err != nil && r.OnError != "evaluate" {
+		cr.Status = r.OnError
+		cr.Evidence["error"] = res.Err.Error()
+		if cr.Status != "pass" {
+			cr.Recommendation = r.Recommendation
+		}
+		return cr
+	}
+
+	value, found := r.Extract.value(res.Stdout)
+	if found {
+		cr.Evidence["value"] = value
+	}
+
+	cr.Status = r.evaluate(value, found)
+	if cr.Status != "pass" {
+		cr.Recommendation = r.Recommendation
+	}
+	return cr
+}
+
+// pass → warn → fail の順に評価し、最初に一致したステータスを返す
+func (r *Rule) evaluate(value string, found bool) string {
+	if !r.Pass.empty() && r.Pass.match(value, found) {
+		return "pass"
+	}
+	if !r.Warn.empty() && r.Warn.match(value, found) {
+		return "warn"
+	}
+	if !r.Fail.empty() && r.Fail.match(value, found) {
+		return "fail"
+	}
+	return r.Default
+}
+
+func (c Condition) empty() bool {
+	return c.Equals == nil && c.NotEquals == nil && c.In == nil && c.Contains == "" &&
+		c.Matches == "" && c.Exists == nil && c.Min == nil && c.Max == nil
+}
+
+func (c Condition) match(value string, found bool) bool {
+	if c.Exists != nil && *c.Exists != found {
+		return false
+	}
+	// 値が取れなかった場合は exists: false のみ一致しうる
+	if !found {
+		return c.Exists != nil
+	}
+	if c.Equals != nil && value != *c.Equals {
+		return false
+	}
+	if c.NotEquals != nil && value == *c.NotEquals {
+		return false
+	}
+	if c.In != nil && !contains(c.In, value) {
+		return false
+	}
+	if c.Contains != "" && !strings.Contains(value, c.Contains) {
+		return false
+	}
+	if c.re != nil && !c.re.MatchString(value) {
+		return false
+	}
+	if c.Min != nil || c.Max != nil {
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return false
+		}
+		if c.Min != nil && n < *c.Min {
+			return false
+		}
+		if c.Max != nil && n > *c.Max {
+			return false
+		}
+	}
+	return true
+}
+
+func contains(list []string, v string) bool {
+	for _, s := range list {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,102 @@
+package rules
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/samuraidays/macinsight/internal/executil"
+)
+
+// 固定の実行結果を返す context を作る
+func fakeCtx(stdout string, err error) context.Context {
+	return executil.WithMiddleware(context.Background(), func(executil.RunFunc) executil.RunFunc {
+		return func(ctx context.Context, c executil.Command) executil.Result {
+			return executil.Result{Stdout: stdout, Err: err}
+		}
+	})
+}
+
+func TestLoadDir_LoadsYAMLAndJSON(t *testing.T) {
+	rules, err := LoadDir("testdata")
+	if err != nil {
+		t.Fatalf("LoadDir error: %v", err)
+	}
+	if len(rules) != 2 {
+		t.Fatalf("expected 2 rules, got %d", len(rules))
+	}
+	c := rules[0].Check()
+	if c.ID() != "org.gatekeeper" || c.Weight() != 5 || c.Category() != "custom" {
+		t.Fatalf("unexpected metadata: id=%s weight=%d category=%s", c.ID(), c.Weight(), c.Category())
+	}
+	if cmds := c.Commands(); len(cmds) != 1 || cmds[0][0] != "/usr/sbin/spctl" {
+		t.Fatalf("unexpected commands: %v", cmds)
+	}
+}
+
+func TestRule_RegexExtraction(t *testing.T) {
+	r, err := LoadFile("testdata/gatekeeper_regex.yaml")
+	if err != nil {
+		t.Fatalf("LoadFile error: %v", err)
+	}
+
+	cr := r.Run(fakeCtx("assessments enabled\n", nil))
+	if cr.Status != "pass" || cr.Evidence["value"] != "enabled" {
+		t.Fatalf("expected pass with value=enabled, got %s %v", cr.Status, cr.Evidence)
+	}
+
+	cr = r.Run(fakeCtx("assessments disabled\n", nil))
+	if cr.Status != "fail" || cr.Recommendation == "" {
+		t.Fatalf("expected fail with recommendation, got %s %q", cr.Status, cr.Recommendation)
+	}
+}
+
+func TestRule_JSONThresholds(t *testing.T) {
+	r, err := LoadFile("testdata/password_policy.json")
+	if err != nil {
+		t.Fatalf("LoadFile error: %v", err)
+	}
+
+	cases := map[string]string{
+		`{"policy":{"minLength":14}}`: "pass",
+		`{"policy":{"minLength":10}}`: "warn",
+		`{"policy":{"minLength":4}}`:  "fail",
+		`{"policy":{}}`:               "fail",
+	}
+	for out, want := range cases {
+		if got := r.Run(fakeCtx(out, nil)).Status; got != want {
+			t.Errorf("output %s: status=%s, want %s", out, got, want)
+		}
+	}
+
+	if got := r.Run(fakeCtx("", errors.New("exec error"))).Status; got != "unknown" {
+		t.Fatalf("command error should map to on_error, got %s", got)
+	}
+}
+
+func TestLoadFile_RejectsInvalidRules(t *testing.T) {
+	for name, body := range map[string]string{
+		"missing_command.yaml": "id: a\ntitle: A\npass: {equals: x}\n",
+		"no_condition.yaml":    "id: a\ntitle: A\ncommand: {name: /bin/true}\n",
+		"bad_id.yaml":          "id: Bad ID\ntitle: A\ncommand: {name: /bin/true}\npass: {equals: x}\n",
+		"unknown_field.yaml":   "id: a\ntitle: A\ncommand: {name: /bin/true}\npass: {equal: x}\n",
+		"bad_extract.yaml":     "id: a\ntitle: A\ncommand: {name: /bin/true}\nextract: {type: xpath}\npass: {equals: x}\n",
+		"bad_severity.yaml":    "id: a\ntitle: A\nseverity: urgent\ncommand: {name: /bin/true}\npass: {equals: x}\n",
+	} {
+		path := filepath.Join(t.TempDir(), name)
+		if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadFile(path); err == nil {
+			t.Errorf("%s should be rejected", name)
+		}
+	}
+}
+
+func TestLoadDir_Examples(t *testing.T) {
+	if _, err := LoadDir("../../examples/rules"); err != nil {
+		t.Fatalf("example rules should load: %v", err)
+	}
+}
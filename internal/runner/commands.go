@@ -0,0 +1,134 @@
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sort"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/samuraidays/macinsight/internal/executil"
+	"github.com/samuraidays/macinsight/pkg/types"
+)
+
+// 結果に残す標準エラー出力の最大バイト数
+const maxStderr = 1024
+
+// commandLog はチェック1件の中で実行したコマンドを集める
+type commandLog struct {
+	mu   sync.Mutex
+	list []types.CommandRun
+}
+
+func (l *commandLog) middleware() executil.Middleware {
+	return func(next executil.RunFunc) executil.RunFunc {
+		return func(ctx context.Context, c executil.Command) executil.Result {
+			res := next(ctx, c)
+			run := commandRun(c, res)
+			l.mu.Lock()
+			l.list = append(l.list, run)
+			l.mu.Unlock()
+			return res
+		}
+	}
+}
+
+func (l *commandLog) runs() []types.CommandRun {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]types.CommandRun(nil), l.list...)
+}
+
+// commandRun は実行結果を結果に残す形に変換する
+func commandRun(c executil.Command, res executil.Result) types.CommandRun {
+	run := types.CommandRun{
+		Argv:        c.Argv(),
+		CommandLine: c.CommandLine(),
+		ExitCode:    res.ExitCode,
+		DurationMs:  res.Duration.Milliseconds(),
+		Stderr:      truncate(res.Stderr, maxStderr),
+		Cached:      res.Cached,
+		Truncated:   res.Truncated,
+	}
+	if res.Err != nil {
+		run.Error = res.Err.Error()
+		run.ErrorKind = string(executil.KindOf(res.Err))
+	}
+	return run
+}
+
+// trail は監査全体で実行したコマンドを、どのチェックが実行したかと合わせて記録する
+type trail struct {
+	mu   sync.Mutex
+	w    io.Writer // nil 以外なら1件ごとに JSON Lines で書き出す（--trace）
+	list []types.CommandRecord
+}
+
+func (t *trail) middleware() executil.Middleware {
+	return func(next executil.RunFunc) executil.RunFunc {
+		return func(ctx context.Context, c executil.Command) executil.Result {
+			start := time.Now()
+			res := next(ctx, c)
+			rec := types.CommandRecord{
+				Check:      checkID(ctx),
+				StartedAt:  start,
+				CommandRun: commandRun(c, res),
+			}
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.list = append(t.list, rec)
+			if t.w != nil {
+				// 書き込みに失敗しても監査は続ける
+				b, _ := json.Marshal(rec)
+				_, _ = t.w.Write(append(b, '\n'))
+			}
+			return res
+		}
+	}
+}
+
+// records は記録したコマンドを、ホスト情報の取得・results のチェック順に、それぞれ開始順で返す
+// 並列実行の完了順に左右されないようにする
+func (t *trail) records(results []types.CheckResult) []types.CommandRecord {
+	order := map[string]int{"": -1}
+	for i, cr := range results {
+		order[cr.ID] = i
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	list := append([]types.CommandRecord(nil), t.list...)
+	sort.SliceStable(list, func(i, j int) bool {
+		a, b := list[i], list[j]
+		if order[a.Check] != order[b.Check] {
+			return order[a.Check] < order[b.Check]
+		}
+		return a.StartedAt.Before(b.StartedAt)
+	})
+	return list
+}
+
+type checkIDKey struct{}
+
+// withCheckID はコマンドを実行するチェックの ID を context に載せる
+func withCheckID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, checkIDKey{}, id)
+}
+
+func checkID(ctx context.Context) string {
+	id, _ := ctx.Value(checkIDKey{}).(string)
+	return id
+}
+
+// truncate は s を n バイト以内に切り詰める（UTF-8 の途中では切らない）
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	cut := n
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut] + "...(truncated)"
+}
@@ -2,85 +2,378 @@ package runner
 
 import (
 	"context"
+	"fmt"
+	"io"
 	"os"
-	"os/exec"
+	"runtime"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/samuraidays/macinsight/internal/checks"
+	"github.com/samuraidays/macinsight/internal/executil"
+	"github.com/samuraidays/macinsight/internal/profile"
+	"github.com/samuraidays/macinsight/internal/scoring"
+	"github.com/samuraidays/macinsight/internal/waiver"
 	"github.com/samuraidays/macinsight/pkg/types"
 )
 
 // CLIから渡す実行オプション
 type Option struct {
-	Only    map[string]struct{} // 実行するチェックを限定（空なら全件）
-	Exclude map[string]struct{} // 実行しないチェック
-	Timeout time.Duration       // チェックごとのタイムアウト
+	Only     map[string]struct{} // 実行するチェックを限定（空なら全件）
+	Exclude  map[string]struct{} // 実行しないチェック
+	Timeout  time.Duration       // チェックごとのタイムアウト
+	Parallel int                 // 同時に実行するチェック数の上限（0 以下なら無制限）
+
+	Timeouts      map[string]time.Duration     // チェック別タイムアウト（Timeout を上書き）
+	Weights       map[string]int               // チェック別の配点上書き
+	Params        map[string]map[string]string // チェック別パラメータ
+	UnknownPolicy string                       // unknown 結果の採点方法（scoring.Unknown*、空なら partial）
+	Platform      string                       // 実行プラットフォーム（空なら runtime.GOOS）
+	Hostname      string                       // ホスト名（空なら os.Hostname）
+	Waivers       []waiver.Waiver              // 例外承認（一致した fail / warn の結果を waived にする）
+	Now           time.Time                    // 例外の期限判定に使う現在時刻（ゼロ値なら time.Now）
+	Profile       *profile.Profile             // 実行するチェック・配点・重要度の組（nil なら全チェック）
+	Root          bool                         // root 権限で実行しているか（false なら root が必要なチェックは skipped）
+
+	// コマンド実行に差し込むミドルウェア（記録・再生など）
+	Middlewares []executil.Middleware
+
+	Debug io.Writer // nil 以外ならコマンドキャッシュのヒットなどを書き出す
+	Trace io.Writer // nil 以外なら実行したコマンドを1件ごとに JSON Lines で書き出す（完了順）
+
+	// 進行イベントを受け取る関数（nil なら通知しない）
+	// 呼び出しは直列化されるため、関数側で排他する必要は無い
+	OnEvent func(types.Event)
+}
+
+// HostCommands はチェックとは別にホスト情報の取得で実行するコマンド
+var HostCommands = [][]string{
+	{"/usr/bin/sw_vers"},
+	{"/usr/sbin/ioreg", "-rd1", "-c", "IOPlatformExpertDevice"},
 }
 
 // 監査実行（並列に各チェックを走らせ、スコア集計して返す）
-func Run(version string, opt Option) types.Report {
+// ctx が終了した場合は実行中・未実行のチェックを cancelled として部分的なレポートを返す
+func Run(ctx context.Context, version string, opt Option) types.Report {
+	started := time.Now()
+	for _, mw := range opt.Middlewares {
+		ctx = executil.WithMiddleware(ctx, mw)
+	}
+	// 同じコマンドの実行結果を監査内で共有する（記録・再生より外側）
+	cache := executil.NewCache()
+	cache.Debug = opt.Debug
+	ctx = executil.WithMiddleware(ctx, cache.Middleware())
+	// 監査証跡（キャッシュより外側で、再利用した結果も記録する）
+	audit := &trail{w: opt.Trace}
+	ctx = executil.WithMiddleware(ctx, audit.middleware())
+
 	host := types.HostInfo{
-		Hostname: hostname(),
-		OS:       osinfo(),
+		Hostname: opt.Hostname,
+		Serial:   serial(ctx),
+		OS:       osinfo(ctx),
+	}
+	if host.Hostname == "" {
+		host.Hostname = hostname()
+	}
+
+	platform := opt.Platform
+	if platform == "" {
+		platform = runtime.GOOS
+	}
+
+	registry := opt.selected()
+
+	// 例外の期限判定に使う現在時刻
+	now := opt.Now
+	if now.IsZero() {
+		now = time.Now()
 	}
 
-	// v0.1: 代表6チェックを登録
-	registry := map[string]func(context.Context) types.CheckResult{
-		"gatekeeper": checks.Gatekeeper,
-		"filevault":  checks.FileVault,
-		"sip":        checks.SIP,
-		"firewall":   checks.Firewall,
-		"autologin":  checks.AutoLogin,
-		"osupdate":   checks.OSUpdate,
+	ev := &events{fn: opt.OnEvent}
+	info := types.RunInfo{Version: version, Host: host, Privileged: opt.Root}
+	if opt.Profile != nil {
+		info.Profile = opt.Profile.Name
+	}
+	for _, c := range registry {
+		info.Checks = append(info.Checks, c.ID())
 	}
+	ev.emit(types.Event{Event: types.EventRunStarted, Run: &info})
 
-	results := make([]types.CheckResult, 0, len(registry))
+	// 結果は完了順ではなく実行対象の順（登録順・プロファイル順）に並べる
+	results := make([]types.CheckResult, len(registry))
 	var wg sync.WaitGroup
-	var mu sync.Mutex
 
-	for id, fn := range registry {
+	// 結果を確定する（例外承認と採点を適用して check_finished を通知）
+	finish := func(i int, cr types.CheckResult) {
+		rs := []types.CheckResult{cr}
+		waiver.Apply(rs, host, opt.Waivers, now)
+		rs[0].Score, _ = scoring.Points(rs[0].Status, rs[0].Weight, opt.UnknownPolicy)
+		results[i] = rs[0]
+		ev.emit(types.Event{Event: types.EventCheckFinished, ID: rs[0].ID, Result: &rs[0]})
+	}
+
+	// 同時実行数の上限
+	var sem chan struct{}
+	if opt.Parallel > 0 {
+		sem = make(chan struct{}, opt.Parallel)
+	}
+
+	for i, c := range registry {
+		// 対象外プラットフォームは実行せず not_applicable とする
+		if !supports(c, platform) {
+			finish(i, opt.annotate(c, notApplicable(c, platform)))
+			continue
+		}
+		// root が必要なチェックは権限が無ければ実行せず skipped とする
+		if c.RequiresRoot() && !opt.Root {
+			finish(i, opt.annotate(c, skipped(c, "requires root (run with sudo)")))
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, c checks.Check) {
+			defer wg.Done()
+			finish(i, opt.annotate(c, opt.runCheck(ctx, c, sem, ev)))
+		}(i, c)
+	}
+
+	wg.Wait()
+
+	if opt.Debug != nil {
+		hits, misses := cache.Stats()
+		fmt.Fprintf(opt.Debug, "command cache: %d hit(s), %d command(s) executed\n", hits, misses)
+	}
+
+	// 採点対象の配点に対する割合でスコアを算出
+	sum := scoring.Apply(results, opt.UnknownPolicy)
+
+	finished := time.Now()
+	rep := types.Report{
+		Version:     version,
+		Host:        host,
+		Profile:     info.Profile,
+		StartedAt:   started,
+		FinishedAt:  finished,
+		DurationMs:  finished.Sub(started).Milliseconds(),
+		Privileged:  opt.Root,
+		Score:       sum.Score,
+		MaxScore:    sum.MaxScore,
+		EarnedScore: sum.EarnedScore,
+		Grade:       sum.Grade,
+		Categories:  sum.Categories,
+		Compliance:  scoring.Compliance(results),
+		Checks:      results,
+		Commands:    audit.records(results),
+	}
+
+	totals := rep.Totals()
+	ev.emit(types.Event{Event: types.EventRunFinished, Totals: &totals})
+	return rep
+}
+
+// runCheck は同時実行数の枠を取ってチェックを実行する
+// 開始前または実行中に ctx が終了した場合は cancelled を返す
+func (o Option) runCheck(ctx context.Context, c checks.Check, sem chan struct{}, ev *events) types.CheckResult {
+	if sem != nil {
+		select {
+		case sem <- struct{}{}:
+			defer func() { <-sem }()
+		case <-ctx.Done():
+			return cancelled(ctx, c)
+		}
+	}
+	if ctx.Err() != nil {
+		return cancelled(ctx, c)
+	}
+
+	// 各チェックに個別タイムアウトとパラメータを適用
+	// 実行したコマンドはチェックから見える結果（再生時は記録内容）を残す
+	start := time.Now()
+	ev.emit(types.Event{Event: types.EventCheckStarted, ID: c.ID()})
+	log := &commandLog{}
+	timeout := o.timeout(c.ID())
+	cctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	cctx = executil.WithMiddleware(cctx, log.middleware())
+	cctx = checks.WithParams(cctx, o.params(c.ID()))
+	cctx = checks.WithTimeout(cctx, timeout)
+	cctx = withCheckID(cctx, c.ID())
+	cr := c.Run(cctx)
+	if ctx.Err() != nil {
+		cr = cancelled(ctx, c)
+	}
+	cr.StartedAt = &start
+	cr.DurationMs = time.Since(start).Milliseconds()
+	cr.Commands = log.runs()
+	return cr
+}
+
+// events は進行イベントを直列化して通知する
+type events struct {
+	mu sync.Mutex
+	fn func(types.Event)
+}
+
+func (e *events) emit(ev types.Event) {
+	if e.fn == nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	ev.Time = time.Now()
+	e.fn(ev)
+}
+
+// RootRequired は root 権限が必要で、権限が無いと skipped になるチェックの ID を返す
+func RootRequired(opt Option) []string {
+	platform := opt.Platform
+	if platform == "" {
+		platform = runtime.GOOS
+	}
+	var ids []string
+	for _, c := range opt.selected() {
+		if c.RequiresRoot() && supports(c, platform) {
+			ids = append(ids, c.ID())
+		}
+	}
+	return ids
+}
+
+// 結果にチェックのメタデータ（配点・カテゴリ・重要度・管理策）を付ける
+func (o Option) annotate(c checks.Check, cr types.CheckResult) types.CheckResult {
+	cr.Weight = o.weight(c)
+	cr.Category = c.Category()
+	cr.Severity = o.severity(c)
+	cr.Controls = c.Controls()
+	return cr
+}
+
+// --only / --exclude を適用した実行対象のチェック
+func (o Option) selected() []checks.Check {
+	var list []checks.Check
+	for _, c := range o.checks() {
+		id := c.ID()
 		// --only が指定されたらその集合にあるものだけ
-		if len(opt.Only) > 0 {
-			if _, ok := opt.Only[id]; !ok {
+		if len(o.Only) > 0 {
+			if _, ok := o.Only[id]; !ok {
 				continue
 			}
 		}
 		// --exclude は除外
-		if _, skip := opt.Exclude[id]; skip {
+		if _, skip := o.Exclude[id]; skip {
 			continue
 		}
+		list = append(list, c)
+	}
+	return list
+}
 
-		wg.Add(1)
-		go func(id string, fn func(context.Context) types.CheckResult) {
-			defer wg.Done()
-			// 各チェックに個別タイムアウトを適用
-			cctx, cancel := context.WithTimeout(context.Background(), opt.Timeout)
-			defer cancel()
-			cr := fn(cctx)
-			mu.Lock()
-			results = append(results, cr)
-			mu.Unlock()
-		}(id, fn)
+// 実行対象のチェック（プロファイル指定時はその順序）
+func (o Option) checks() []checks.Check {
+	if o.Profile == nil {
+		return checks.All()
 	}
+	list := make([]checks.Check, 0, len(o.Profile.Checks))
+	for _, e := range o.Profile.Checks {
+		if c, ok := checks.Lookup(e.ID); ok {
+			list = append(list, c)
+		}
+	}
+	return list
+}
 
-	wg.Wait()
+// 配点（Weights > プロファイル > チェックの既定値）
+func (o Option) weight(c checks.Check) int {
+	if w, ok := o.Weights[c.ID()]; ok {
+		return w
+	}
+	if e, ok := o.profileEntry(c.ID()); ok && e.Weight != nil {
+		return *e.Weight
+	}
+	return c.Weight()
+}
+
+// パラメータ（プロファイルの値に Params をキー単位で重ねる）
+func (o Option) params(id string) map[string]string {
+	e, _ := o.profileEntry(id)
+	if len(e.Params) == 0 {
+		return o.Params[id]
+	}
+	merged := map[string]string{}
+	for k, v := range e.Params {
+		merged[k] = v
+	}
+	for k, v := range o.Params[id] {
+		merged[k] = v
+	}
+	return merged
+}
 
-	// 合計スコア（上限100）
-	total := 0
-	for _, r := range results {
-		total += r.Score
+// 重要度（プロファイル > チェックの既定値）
+func (o Option) severity(c checks.Check) string {
+	if e, ok := o.profileEntry(c.ID()); ok && e.Severity != "" {
+		return e.Severity
 	}
-	if total > 100 {
-		total = 100
+	return c.Severity()
+}
+
+func (o Option) profileEntry(id string) (profile.Entry, bool) {
+	if o.Profile == nil {
+		return profile.Entry{}, false
+	}
+	return o.Profile.Entry(id)
+}
+
+// タイムアウト（チェック別指定があれば優先）
+func (o Option) timeout(id string) time.Duration {
+	if d, ok := o.Timeouts[id]; ok {
+		return d
+	}
+	return o.Timeout
+}
+
+func supports(c checks.Check, platform string) bool {
+	if len(c.Platforms()) == 0 {
+		return true
+	}
+	for _, p := range c.Platforms() {
+		if p == platform {
+			return true
+		}
+	}
+	return false
+}
+
+func notApplicable(c checks.Check, platform string) types.CheckResult {
+	return types.CheckResult{
+		ID:       c.ID(),
+		Title:    c.Title(),
+		Status:   "not_applicable",
+		Reason:   "not supported on " + platform,
+		Evidence: map[string]string{"platform": platform},
+	}
+}
+
+// 実行しなかったチェックの結果（採点対象外）
+func skipped(c checks.Check, reason string) types.CheckResult {
+	return types.CheckResult{
+		ID:             c.ID(),
+		Title:          c.Title(),
+		Status:         "skipped",
+		Reason:         reason,
+		Recommendation: "sudo で実行すると評価されます",
 	}
+}
 
-	return types.Report{
-		Version: version,
-		Host:    host,
-		Score:   total,
-		Checks:  results,
+// 中断されたチェックの結果（採点対象外）
+func cancelled(ctx context.Context, c checks.Check) types.CheckResult {
+	return types.CheckResult{
+		ID:             c.ID(),
+		Title:          c.Title(),
+		Status:         "cancelled",
+		Reason:         context.Cause(ctx).Error(),
+		Recommendation: "--deadline を延ばすか、中断せずに再実行",
 	}
 }
 
@@ -90,9 +383,9 @@ func hostname() string {
 }
 
 // sw_vers を呼んで OS 情報を得る（失敗は空値で返す）
-func osinfo() types.OSInfo {
-	out, _ := exec.Command("/usr/bin/sw_vers").Output()
-	s := string(out)
+func osinfo(ctx context.Context) types.OSInfo {
+	res := executil.Run(ctx, 3*time.Second, "/usr/bin/sw_vers")
+	s := res.Stdout
 	return types.OSInfo{
 		Product: "macOS",
 		Version: lineValue(s, "ProductVersion:"),
@@ -100,6 +393,20 @@ func osinfo() types.OSInfo {
 	}
 }
 
+// ioreg からハードウェアのシリアル番号を得る（失敗は空値）
+func serial(ctx context.Context) string {
+	res := executil.Run(ctx, 3*time.Second, "/usr/sbin/ioreg", "-rd1", "-c", "IOPlatformExpertDevice")
+	for _, l := range strings.Split(res.Stdout, "\n") {
+		if !strings.Contains(l, `"IOPlatformSerialNumber"`) {
+			continue
+		}
+		if i := strings.Index(l, "="); i >= 0 {
+			return strings.Trim(strings.TrimSpace(l[i+1:]), `"`)
+		}
+	}
+	return ""
+}
+
 func lineValue(s, key string) string {
 	for _, l := range strings.Split(s, "\n") {
 		lt := strings.TrimSpace(l)
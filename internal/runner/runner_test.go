@@ -0,0 +1,451 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/samuraidays/macinsight/internal/checks"
+	"github.com/samuraidays/macinsight/internal/executil"
+	"github.com/samuraidays/macinsight/internal/profile"
+	"github.com/samuraidays/macinsight/internal/waiver"
+	"github.com/samuraidays/macinsight/pkg/types"
+)
+
+// root が必要なチェック（組み込みチェックには無いため、テスト用に登録して通常は除外する）
+const rootOnlyID = "test.root_only"
+
+func init() {
+	checks.MustRegister(checks.New(checks.Definition{
+		ID:           rootOnlyID,
+		Title:        "Root-only test check",
+		Weight:       10,
+		Category:     "custom",
+		Platforms:    []string{"darwin"},
+		RequiresRoot: true,
+	}, func(ctx context.Context) types.CheckResult {
+		return types.CheckResult{ID: rootOnlyID, Title: "Root-only test check", Status: "pass"}
+	}))
+}
+
+func replayOption(t *testing.T) Option {
+	t.Helper()
+	rp, err := executil.LoadReplayer("testdata/sample-mac")
+	if err != nil {
+		t.Fatalf("LoadReplayer error: %v", err)
+	}
+	return Option{
+		Timeout:     3 * time.Second,
+		Hostname:    rp.Manifest.Hostname,
+		Platform:    rp.Manifest.Platform,
+		Root:        rp.Manifest.Root,
+		Exclude:     map[string]struct{}{rootOnlyID: {}},
+		Middlewares: []executil.Middleware{rp.Middleware()},
+	}
+}
+
+func byID(rep types.Report) map[string]types.CheckResult {
+	m := map[string]types.CheckResult{}
+	for _, c := range rep.Checks {
+		m[c.ID] = c
+	}
+	return m
+}
+
+func TestRun_ReplaysFixtureBundle(t *testing.T) {
+	rep := Run(context.Background(), "vtest", replayOption(t))
+
+	if rep.Host.Hostname != "sample-mac" || rep.Host.Serial != "C02SAMPLE01" || rep.Host.OS.Version != "15.6.1" || rep.Host.OS.Build != "24G90" {
+		t.Fatalf("host info not replayed: %+v", rep.Host)
+	}
+
+	got := byID(rep)
+	want := map[string]string{
+		"sip":        "pass",
+		"gatekeeper": "pass",
+		"filevault":  "fail",
+		"firewall":   "pass",
+		"autologin":  "pass",
+		"osupdate":   "pass",
+	}
+	for id, status := range want {
+		if got[id].Status != status {
+			t.Errorf("%s: status=%s, want %s", id, got[id].Status, status)
+		}
+	}
+	if rep.MaxScore != 100 || rep.EarnedScore != 80 || rep.Score != 80 {
+		t.Fatalf("score mismatch: score=%d earned=%d max=%d", rep.Score, rep.EarnedScore, rep.MaxScore)
+	}
+
+	if rep.Grade != "B" {
+		t.Errorf("grade = %s, want B", rep.Grade)
+	}
+	if fv := got["filevault"]; fv.Severity != "critical" || fv.Category != "encryption" {
+		t.Errorf("filevault severity=%s category=%s, want critical encryption", fv.Severity, fv.Category)
+	}
+	for _, c := range rep.Categories {
+		want := 100
+		if c.Category == "encryption" {
+			want = 0
+		}
+		if c.Score != want {
+			t.Errorf("category %s score = %d, want %d", c.Category, c.Score, want)
+		}
+	}
+
+	// FileVault の不合格が各フレームワークの合格率に反映される
+	if len(rep.Compliance) != 3 {
+		t.Fatalf("compliance frameworks = %d, want 3: %+v", len(rep.Compliance), rep.Compliance)
+	}
+	for _, c := range rep.Compliance {
+		if c.Passed != 5 || c.Total != 6 || c.PassRate != 83 {
+			t.Errorf("%s: passed=%d total=%d rate=%d, want 5/6 83", c.Framework, c.Passed, c.Total, c.PassRate)
+		}
+	}
+	if len(got["filevault"].Controls) == 0 {
+		t.Errorf("filevault result has no controls")
+	}
+}
+
+func TestRun_WaivedChecksAreNotScored(t *testing.T) {
+	opt := replayOption(t)
+	opt.Now = time.Date(2026, 10, 1, 0, 0, 0, 0, time.Local)
+	opt.Waivers = loadWaivers(t, `
+waivers:
+  - check: filevault
+    serial: C02SAMPLE01
+    owner: it-security
+    justification: loaner device without user data
+    expires: 2026-12-31
+`)
+
+	rep := Run(context.Background(), "vtest", opt)
+	if got := byID(rep)["filevault"].Status; got != "waived" {
+		t.Fatalf("filevault should be waived, got %s", got)
+	}
+	if rep.MaxScore != 80 || rep.Score != 100 {
+		t.Fatalf("waived check should be excluded from scoring: score=%d max=%d", rep.Score, rep.MaxScore)
+	}
+}
+
+func loadWaivers(t *testing.T, body string) []waiver.Waiver {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "waivers.yaml")
+	if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	ws, err := waiver.Load(path)
+	if err != nil {
+		t.Fatalf("waiver.Load error: %v", err)
+	}
+	return ws
+}
+
+func TestRun_ProfileSelectsChecksAndWeights(t *testing.T) {
+	p, err := profile.Get("cis-l2")
+	if err != nil {
+		t.Fatalf("profile.Get error: %v", err)
+	}
+	opt := replayOption(t)
+	opt.Profile = p
+	opt.Weights = map[string]int{"sip": 30}
+
+	rep := Run(context.Background(), "vtest", opt)
+	if rep.Profile != "cis-l2" || len(rep.Checks) != len(p.Checks) {
+		t.Fatalf("profile not applied: profile=%q checks=%d", rep.Profile, len(rep.Checks))
+	}
+	got := byID(rep)
+	if got["osupdate"].Weight != 25 || got["osupdate"].Severity != "critical" {
+		t.Fatalf("profile weight/severity not applied: %+v", got["osupdate"])
+	}
+	if got["sip"].Weight != 30 {
+		t.Fatalf("explicit weights should override the profile, got %d", got["sip"].Weight)
+	}
+}
+
+func TestRun_ParallelBoundsConcurrentChecks(t *testing.T) {
+	opt := replayOption(t)
+	opt.Parallel = 2
+
+	// 再生の外側で同時に実行中のコマンド数を数える
+	var mu sync.Mutex
+	inFlight, peak := 0, 0
+	opt.Middlewares = append(opt.Middlewares, func(next executil.RunFunc) executil.RunFunc {
+		return func(ctx context.Context, c executil.Command) executil.Result {
+			mu.Lock()
+			inFlight++
+			if inFlight > peak {
+				peak = inFlight
+			}
+			mu.Unlock()
+			time.Sleep(20 * time.Millisecond)
+			defer func() {
+				mu.Lock()
+				inFlight--
+				mu.Unlock()
+			}()
+			return next(ctx, c)
+		}
+	})
+
+	rep := Run(context.Background(), "vtest", opt)
+	if rep.EarnedScore != 80 {
+		t.Fatalf("earned = %d, want 80", rep.EarnedScore)
+	}
+	// ホスト情報の取得はチェック開始前に終わるため、チェック実行中の上限は Parallel
+	if peak > 2 {
+		t.Fatalf("peak concurrent commands = %d, want <= 2", peak)
+	}
+}
+
+func TestRun_DeadlineMarksInterruptedChecksCancelled(t *testing.T) {
+	opt := replayOption(t)
+	opt.Parallel = 1
+
+	// filevault のコマンドだけ ctx が終わるまで戻らない
+	opt.Middlewares = append(opt.Middlewares, func(next executil.RunFunc) executil.RunFunc {
+		return func(ctx context.Context, c executil.Command) executil.Result {
+			if strings.Contains(c.Name, "fdesetup") {
+				<-ctx.Done()
+				return executil.Result{ExitCode: -1, Err: ctx.Err()}
+			}
+			return next(ctx, c)
+		}
+	})
+
+	ctx, cancel := context.WithTimeoutCause(context.Background(), 200*time.Millisecond, errors.New("audit deadline exceeded"))
+	defer cancel()
+	opt.Timeout = 5 * time.Second
+	rep := Run(ctx, "vtest", opt)
+
+	got := byID(rep)
+	if len(got) != 6 {
+		t.Fatalf("partial report should still contain every check, got %d", len(got))
+	}
+	fv := got["filevault"]
+	if fv.Status != "cancelled" || fv.Reason != "audit deadline exceeded" {
+		t.Fatalf("filevault = %s %q, want cancelled with reason", fv.Status, fv.Reason)
+	}
+	for id, c := range got {
+		if c.Status != "pass" && c.Status != "cancelled" {
+			t.Errorf("%s: status=%s, want pass or cancelled", id, c.Status)
+		}
+	}
+	if rep.MaxScore >= 100 {
+		t.Errorf("cancelled checks should not be scored, max=%d", rep.MaxScore)
+	}
+}
+
+func TestRun_RecordsTimingAndCommands(t *testing.T) {
+	rep := Run(context.Background(), "vtest", replayOption(t))
+
+	if rep.StartedAt.IsZero() || rep.FinishedAt.Before(rep.StartedAt) || rep.DurationMs < 0 {
+		t.Fatalf("report timing not set: started=%v finished=%v duration=%d", rep.StartedAt, rep.FinishedAt, rep.DurationMs)
+	}
+
+	fv := byID(rep)["filevault"]
+	if fv.StartedAt == nil || fv.StartedAt.Before(rep.StartedAt) {
+		t.Fatalf("filevault started_at = %v, want after report start", fv.StartedAt)
+	}
+	if len(fv.Commands) != 1 {
+		t.Fatalf("filevault commands = %+v, want 1", fv.Commands)
+	}
+	cmd := fv.Commands[0]
+	if strings.Join(cmd.Argv, " ") != "/usr/bin/fdesetup status" || cmd.ExitCode != 0 || cmd.DurationMs != 40 {
+		t.Fatalf("filevault command = %+v", cmd)
+	}
+	if cmd.CommandLine != "LANG=C LC_ALL=C /usr/bin/fdesetup status" {
+		t.Fatalf("command line = %q", cmd.CommandLine)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	if got := truncate("short", 10); got != "short" {
+		t.Errorf("truncate(short) = %q", got)
+	}
+	// マルチバイト文字の途中では切らない
+	if got := truncate("あいう", 4); got != "あ...(truncated)" {
+		t.Errorf("truncate(あいう, 4) = %q", got)
+	}
+}
+
+func TestRun_SkipsRootOnlyChecksWithoutPrivileges(t *testing.T) {
+	opt := replayOption(t)
+	opt.Exclude = nil
+	opt.Root = false
+
+	if ids := RootRequired(opt); strings.Join(ids, ",") != rootOnlyID {
+		t.Fatalf("RootRequired = %v, want only %s", ids, rootOnlyID)
+	}
+
+	rep := Run(context.Background(), "vtest", opt)
+	got := byID(rep)
+	if c := got[rootOnlyID]; c.Status != "skipped" || c.Reason == "" || c.Commands != nil {
+		t.Errorf("status=%s reason=%q commands=%v, want skipped without running", c.Status, c.Reason, c.Commands)
+	}
+	// fdesetup / socketfilterfw は root 無しでも判定できる（FileVault が無効なら fail のまま）
+	if got["filevault"].Status != "fail" || got["firewall"].Status != "pass" {
+		t.Errorf("filevault=%s firewall=%s, want evaluated without root", got["filevault"].Status, got["firewall"].Status)
+	}
+	// skipped は採点対象外
+	if rep.Privileged || rep.MaxScore != 100 || rep.Score != 80 {
+		t.Fatalf("privileged=%v score=%d max=%d, want false 80 100", rep.Privileged, rep.Score, rep.MaxScore)
+	}
+}
+
+func TestRun_SharesSwVersBetweenHostInfoAndOSUpdate(t *testing.T) {
+	var debug bytes.Buffer
+	opt := replayOption(t)
+	opt.Debug = &debug
+
+	rep := Run(context.Background(), "vtest", opt)
+	cmds := byID(rep)["osupdate"].Commands
+	if len(cmds) == 0 || cmds[0].Argv[0] != "/usr/bin/sw_vers" || !cmds[0].Cached {
+		t.Fatalf("osupdate should reuse the host info sw_vers result, got %+v", cmds)
+	}
+	if byID(rep)["osupdate"].Evidence["version"] != "15.6.1" {
+		t.Fatalf("osupdate version = %q", byID(rep)["osupdate"].Evidence["version"])
+	}
+	if !strings.Contains(debug.String(), "cache hit: /usr/bin/sw_vers") || !strings.Contains(debug.String(), "command cache: 1 hit(s)") {
+		t.Fatalf("debug output = %q", debug.String())
+	}
+}
+
+func TestRun_AuditTrailRecordsEveryCommand(t *testing.T) {
+	var trace bytes.Buffer
+	opt := replayOption(t)
+	opt.Trace = &trace
+
+	rep := Run(context.Background(), "vtest", opt)
+
+	declared := map[string]map[string]bool{"": {}}
+	for _, argv := range HostCommands {
+		declared[""][strings.Join(argv, " ")] = true
+	}
+	for _, c := range opt.selected() {
+		declared[c.ID()] = map[string]bool{}
+		for _, argv := range c.Commands() {
+			declared[c.ID()][strings.Join(argv, " ")] = true
+		}
+	}
+
+	// ホスト情報2件 + 各チェックのコマンド7件
+	if len(rep.Commands) != 9 {
+		t.Fatalf("report commands = %d, want 9: %+v", len(rep.Commands), rep.Commands)
+	}
+	for i, rec := range rep.Commands {
+		argv := strings.Join(rec.Argv, " ")
+		if !declared[rec.Check][argv] {
+			t.Errorf("command %q attributed to %q is not declared", argv, rec.Check)
+		}
+		if i > 0 && rec.Check == rep.Commands[i-1].Check && rec.StartedAt.Before(rep.Commands[i-1].StartedAt) {
+			t.Errorf("commands not in start order at %d", i)
+		}
+	}
+
+	lines := strings.Split(strings.TrimSpace(trace.String()), "\n")
+	if len(lines) != len(rep.Commands) {
+		t.Fatalf("trace lines = %d, want %d", len(lines), len(rep.Commands))
+	}
+	var rec types.CommandRecord
+	if err := json.Unmarshal([]byte(lines[0]), &rec); err != nil || len(rec.Argv) == 0 || rec.StartedAt.IsZero() {
+		t.Fatalf("trace line %q: %+v %v", lines[0], rec, err)
+	}
+}
+
+func TestRun_ResultsFollowRegistryOrder(t *testing.T) {
+	opt := replayOption(t)
+	want := []string{}
+	for _, c := range opt.selected() {
+		want = append(want, c.ID())
+	}
+
+	// 先に登録されたチェックほど遅く終わるようにする
+	delay := map[string]time.Duration{}
+	for i, id := range want {
+		delay[id] = time.Duration(len(want)-i) * 10 * time.Millisecond
+	}
+	opt.Middlewares = append(opt.Middlewares, func(next executil.RunFunc) executil.RunFunc {
+		return func(ctx context.Context, c executil.Command) executil.Result {
+			time.Sleep(delay[checkID(ctx)])
+			return next(ctx, c)
+		}
+	})
+
+	rep := Run(context.Background(), "vtest", opt)
+	var got []string
+	for _, c := range rep.Checks {
+		got = append(got, c.ID)
+	}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("checks order = %v, want registry order %v", got, want)
+	}
+	if rep.Commands[0].Check != "" || rep.Commands[len(rep.Commands)-1].Check != want[len(want)-1] {
+		t.Fatalf("commands should list host information first and follow check order: %+v", rep.Commands)
+	}
+}
+
+func TestRun_EmitsProgressEvents(t *testing.T) {
+	opt := replayOption(t)
+	opt.Exclude = nil
+	opt.Root = false // root が必要なチェックは started 無しで finished だけ
+	opt.Now = time.Date(2026, 10, 1, 0, 0, 0, 0, time.Local)
+	opt.Waivers = loadWaivers(t, `
+waivers:
+  - check: filevault
+    owner: it-security
+    justification: test
+    expires: 2026-12-31
+`)
+	var got []types.Event
+	opt.OnEvent = func(ev types.Event) { got = append(got, ev) }
+
+	rep := Run(context.Background(), "vtest", opt)
+
+	if len(got) < 2 || got[0].Event != types.EventRunStarted || got[len(got)-1].Event != types.EventRunFinished {
+		t.Fatalf("events should start with run_started and end with run_finished: %+v", got)
+	}
+	if got[0].Run == nil || len(got[0].Run.Checks) != len(rep.Checks) || got[0].Run.Host.Hostname != "sample-mac" {
+		t.Fatalf("run_started = %+v", got[0].Run)
+	}
+	totals := got[len(got)-1].Totals
+	if totals == nil || totals.Score != rep.Score || totals.Grade != rep.Grade || totals.Statuses["skipped"] != 1 || totals.Statuses["waived"] != 1 {
+		t.Fatalf("run_finished totals = %+v, report score=%d grade=%s", totals, rep.Score, rep.Grade)
+	}
+
+	rootOnly := map[string]bool{}
+	for _, id := range RootRequired(opt) {
+		rootOnly[id] = true
+	}
+	started := map[string]bool{}
+	finished := map[string]types.CheckResult{}
+	for _, ev := range got[1 : len(got)-1] {
+		switch ev.Event {
+		case types.EventCheckStarted:
+			started[ev.ID] = true
+		case types.EventCheckFinished:
+			if !rootOnly[ev.ID] && !started[ev.ID] {
+				t.Errorf("check_finished for %s before check_started", ev.ID)
+			}
+			finished[ev.ID] = *ev.Result
+		default:
+			t.Errorf("unexpected event %q", ev.Event)
+		}
+	}
+	if started[rootOnlyID] || finished[rootOnlyID].Status != "skipped" || finished["filevault"].Status != "waived" {
+		t.Errorf("skipped check should only be finished: started=%v %s=%+v filevault=%+v", started[rootOnlyID], rootOnlyID, finished[rootOnlyID], finished["filevault"])
+	}
+	// 通知した結果は例外承認・採点を適用済みで、レポートと一致する
+	for _, cr := range rep.Checks {
+		if ev := finished[cr.ID]; ev.Status != cr.Status || ev.Score != cr.Score {
+			t.Errorf("%s: event status=%s score=%d, report status=%s score=%d", cr.ID, ev.Status, ev.Score, cr.Status, cr.Score)
+		}
+	}
+}
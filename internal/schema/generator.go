@@ -5,9 +5,36 @@ import (
 	"fmt"
 	"io"
 
+	"github.com/samuraidays/macinsight/internal/checks"
+	"github.com/samuraidays/macinsight/internal/executil"
+	"github.com/samuraidays/macinsight/internal/scoring"
 	"github.com/samuraidays/macinsight/pkg/types"
 )
 
+// statuses lists every valid check result status
+var statuses = []string{"pass", "fail", "warn", "unknown", "not_applicable", "waived", "cancelled", "skipped"}
+
+// errorKinds lists every valid error kind
+func errorKinds() []string {
+	kinds := make([]string, 0, len(executil.ErrorKinds))
+	for _, k := range executil.ErrorKinds {
+		kinds = append(kinds, string(k))
+	}
+	return kinds
+}
+
+func isErrorKind(kind string) bool {
+	for _, k := range executil.ErrorKinds {
+		if string(k) == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// grades lists every valid report grade
+var grades = []string{"A", "B", "C", "D", "F"}
+
 // JSONSchemaGenerator generates JSON Schema from Go structs
 type JSONSchemaGenerator struct{}
 
@@ -33,6 +60,10 @@ func (g *JSONSchemaGenerator) GenerateReportSchema() (map[string]interface{}, er
 						"type":        "string",
 						"description": "Hostname of the audited system",
 					},
+					"serial": map[string]interface{}{
+						"type":        "string",
+						"description": "Hardware serial number",
+					},
 					"os": map[string]interface{}{
 						"type":        "object",
 						"description": "Operating system information",
@@ -58,12 +89,115 @@ func (g *JSONSchemaGenerator) GenerateReportSchema() (map[string]interface{}, er
 				},
 				"required": []string{"hostname", "os"},
 			},
+			"profile": map[string]interface{}{
+				"type":        "string",
+				"description": "Benchmark profile used for the audit",
+			},
 			"score": map[string]interface{}{
 				"type":        "integer",
-				"description": "Total security score (0-100)",
+				"description": "Security score as a percentage of the applicable weight (0-100)",
 				"minimum":     0,
 				"maximum":     100,
 			},
+			"max_score": map[string]interface{}{
+				"type":        "integer",
+				"description": "Sum of the weights of scored checks",
+				"minimum":     0,
+			},
+			"earned_score": map[string]interface{}{
+				"type":        "integer",
+				"description": "Sum of the points earned by scored checks",
+				"minimum":     0,
+			},
+			"started_at": map[string]interface{}{
+				"type":        "string",
+				"format":      "date-time",
+				"description": "Time the audit started",
+			},
+			"finished_at": map[string]interface{}{
+				"type":        "string",
+				"format":      "date-time",
+				"description": "Time the audit finished",
+			},
+			"duration_ms": map[string]interface{}{
+				"type":        "integer",
+				"description": "Wall-clock duration of the whole audit in milliseconds",
+				"minimum":     0,
+			},
+			"privileged": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Whether the audit ran as root; checks that need root are skipped otherwise",
+			},
+			"grade": map[string]interface{}{
+				"type":        "string",
+				"description": "Letter grade derived from score (A >= 90, B >= 80, C >= 70, D >= 60, otherwise F)",
+				"enum":        grades,
+			},
+			"categories": map[string]interface{}{
+				"type":        "array",
+				"description": "Sub-scores per check category",
+				"items": map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"category": map[string]interface{}{
+							"type":        "string",
+							"description": "Check category",
+						},
+						"score": map[string]interface{}{
+							"type":        "integer",
+							"description": "Category score (0-100)",
+							"minimum":     0,
+							"maximum":     100,
+						},
+						"max_score": map[string]interface{}{
+							"type":        "integer",
+							"description": "Sum of the weights of scored checks in the category",
+							"minimum":     0,
+						},
+						"earned_score": map[string]interface{}{
+							"type":        "integer",
+							"description": "Sum of the points earned in the category",
+							"minimum":     0,
+						},
+					},
+					"required": []string{"category", "score", "max_score", "earned_score"},
+				},
+			},
+			"compliance": map[string]interface{}{
+				"type":        "array",
+				"description": "Pass rate per compliance framework (not_applicable and waived checks are not counted)",
+				"items": map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"framework": map[string]interface{}{
+							"type":        "string",
+							"description": "Framework name",
+						},
+						"controls": map[string]interface{}{
+							"type":        "array",
+							"description": "Control IDs covered by the evaluated checks",
+							"items":       map[string]interface{}{"type": "string"},
+						},
+						"passed": map[string]interface{}{
+							"type":        "integer",
+							"description": "Number of passing checks mapped to the framework",
+							"minimum":     0,
+						},
+						"total": map[string]interface{}{
+							"type":        "integer",
+							"description": "Number of evaluated checks mapped to the framework",
+							"minimum":     0,
+						},
+						"pass_rate": map[string]interface{}{
+							"type":        "integer",
+							"description": "Passed checks as a percentage of total (0-100)",
+							"minimum":     0,
+							"maximum":     100,
+						},
+					},
+					"required": []string{"framework", "controls", "passed", "total", "pass_rate"},
+				},
+			},
 			"checks": map[string]interface{}{
 				"type":        "array",
 				"description": "Security check results",
@@ -73,7 +207,7 @@ func (g *JSONSchemaGenerator) GenerateReportSchema() (map[string]interface{}, er
 						"id": map[string]interface{}{
 							"type":        "string",
 							"description": "Check identifier",
-							"enum":        []string{"sip", "gatekeeper", "filevault", "firewall", "autologin", "osupdate"},
+							"enum":        checks.IDs(),
 						},
 						"title": map[string]interface{}{
 							"type":        "string",
@@ -82,13 +216,48 @@ func (g *JSONSchemaGenerator) GenerateReportSchema() (map[string]interface{}, er
 						"status": map[string]interface{}{
 							"type":        "string",
 							"description": "Check result status",
-							"enum":        []string{"pass", "fail", "warn", "unknown"},
+							"enum":        statuses,
+						},
+						"reason": map[string]interface{}{
+							"type":        "string",
+							"description": "Why the check was not evaluated (skipped, cancelled, not_applicable)",
 						},
 						"score": map[string]interface{}{
 							"type":        "integer",
 							"description": "Points awarded for this check",
 							"minimum":     0,
-							"maximum":     20,
+						},
+						"weight": map[string]interface{}{
+							"type":        "integer",
+							"description": "Maximum points for this check",
+							"minimum":     0,
+						},
+						"severity": map[string]interface{}{
+							"type":        "string",
+							"description": "Check severity",
+							"enum":        checks.Severities,
+						},
+						"category": map[string]interface{}{
+							"type":        "string",
+							"description": "Check category",
+						},
+						"controls": map[string]interface{}{
+							"type":        "array",
+							"description": "Compliance framework controls the check maps to",
+							"items": map[string]interface{}{
+								"type": "object",
+								"properties": map[string]interface{}{
+									"framework": map[string]interface{}{
+										"type":        "string",
+										"description": "Framework name",
+									},
+									"id": map[string]interface{}{
+										"type":        "string",
+										"description": "Control identifier within the framework",
+									},
+								},
+								"required": []string{"framework", "id"},
+							},
 						},
 						"evidence": map[string]interface{}{
 							"type":        "object",
@@ -101,17 +270,121 @@ func (g *JSONSchemaGenerator) GenerateReportSchema() (map[string]interface{}, er
 							"type":        "string",
 							"description": "Recommendation for improvement",
 						},
+						"error": map[string]interface{}{
+							"type":        "object",
+							"description": "Classified command failure",
+							"properties": map[string]interface{}{
+								"kind": map[string]interface{}{
+									"type":        "string",
+									"description": "Failure class",
+									"enum":        errorKinds(),
+								},
+								"message": map[string]interface{}{
+									"type":        "string",
+									"description": "Human-readable failure description",
+								},
+							},
+							"required": []string{"kind", "message"},
+						},
+						"started_at": map[string]interface{}{
+							"type":        "string",
+							"format":      "date-time",
+							"description": "Time the check started (absent if it was not run)",
+						},
+						"duration_ms": map[string]interface{}{
+							"type":        "integer",
+							"description": "Wall-clock duration of the check in milliseconds",
+							"minimum":     0,
+						},
+						"commands": map[string]interface{}{
+							"type":        "array",
+							"description": "Commands executed by the check",
+							"items": map[string]interface{}{
+								"type":       "object",
+								"properties": commandProperties(),
+								"required":   []string{"argv", "command_line", "exit_code", "duration_ms"},
+							},
+						},
 					},
-					"required": []string{"id", "title", "status", "score"},
+					"required": []string{"id", "title", "status", "score", "weight"},
+				},
+			},
+			"commands": map[string]interface{}{
+				"type":        "array",
+				"description": "Audit trail of every command executed during the audit: host information first, then in check order",
+				"items": map[string]interface{}{
+					"type":       "object",
+					"properties": commandRecordProperties(),
+					"required":   []string{"started_at", "argv", "command_line", "exit_code", "duration_ms"},
 				},
 			},
 		},
-		"required": []string{"version", "host", "score", "checks"},
+		"required": []string{"version", "host", "started_at", "finished_at", "duration_ms", "privileged", "score", "max_score", "earned_score", "grade", "checks"},
 	}
 
 	return schema, nil
 }
 
+// commandProperties returns the properties of an executed command record
+func commandProperties() map[string]interface{} {
+	return map[string]interface{}{
+		"argv": map[string]interface{}{
+			"type":        "array",
+			"description": "Command name and arguments",
+			"items":       map[string]interface{}{"type": "string"},
+		},
+		"command_line": map[string]interface{}{
+			"type":        "string",
+			"description": "Effective command line including environment overrides and working directory",
+		},
+		"exit_code": map[string]interface{}{
+			"type":        "integer",
+			"description": "Exit code (-1 if the command could not be started)",
+		},
+		"duration_ms": map[string]interface{}{
+			"type":        "integer",
+			"description": "Command duration in milliseconds",
+			"minimum":     0,
+		},
+		"stderr": map[string]interface{}{
+			"type":        "string",
+			"description": "Standard error output, truncated to 1024 bytes",
+		},
+		"error": map[string]interface{}{
+			"type":        "string",
+			"description": "Error starting or waiting for the command",
+		},
+		"cached": map[string]interface{}{
+			"type":        "boolean",
+			"description": "Whether the result was reused from an identical command earlier in the same audit",
+		},
+		"truncated": map[string]interface{}{
+			"type":        "boolean",
+			"description": "Whether output beyond the capture limit was discarded",
+		},
+		"error_kind": map[string]interface{}{
+			"type":        "string",
+			"description": "Failure class of error",
+			"enum":        errorKinds(),
+		},
+	}
+}
+
+// commandRecordProperties adds the triggering check and start time for the audit trail
+func commandRecordProperties() map[string]interface{} {
+	props := commandProperties()
+	props["check"] = map[string]interface{}{
+		"type":        "string",
+		"description": "ID of the check that ran the command (absent for host information)",
+	}
+	props["started_at"] = map[string]interface{}{
+		"type":        "string",
+		"format":      "date-time",
+		"description": "Time the command was started",
+	}
+	return props
+}
+
 // WriteSchema writes the JSON schema to a writer
 func (g *JSONSchemaGenerator) WriteSchema(w io.Writer) error {
 	schema, err := g.GenerateReportSchema()
@@ -137,19 +410,77 @@ func (g *JSONSchemaGenerator) ValidateReport(report types.Report) error {
 		return fmt.Errorf("score must be between 0 and 100, got %d", report.Score)
 	}
 
+	if report.EarnedScore < 0 || report.EarnedScore > report.MaxScore {
+		return fmt.Errorf("earned_score must be between 0 and max_score (%d), got %d", report.MaxScore, report.EarnedScore)
+	}
+
+	if report.FinishedAt.Before(report.StartedAt) || report.DurationMs < 0 {
+		return fmt.Errorf("finished_at must not be before started_at")
+	}
+	if report.Grade != scoring.Grade(report.Score) {
+		return fmt.Errorf("grade must be %s for score %d, got %q", scoring.Grade(report.Score), report.Score, report.Grade)
+	}
+	for _, c := range report.Categories {
+		if c.EarnedScore < 0 || c.EarnedScore > c.MaxScore {
+			return fmt.Errorf("category earned_score must be between 0 and max_score (%d), got %d for %s", c.MaxScore, c.EarnedScore, c.Category)
+		}
+	}
+
+	for _, c := range report.Compliance {
+		if c.Framework == "" {
+			return fmt.Errorf("compliance framework is required")
+		}
+		if c.Passed < 0 || c.Passed > c.Total {
+			return fmt.Errorf("compliance passed must be between 0 and total (%d), got %d for %s", c.Total, c.Passed, c.Framework)
+		}
+		if c.PassRate < 0 || c.PassRate > 100 {
+			return fmt.Errorf("compliance pass_rate must be between 0 and 100, got %d for %s", c.PassRate, c.Framework)
+		}
+	}
+
 	// Validate checks
-	validStatuses := map[string]bool{"pass": true, "fail": true, "warn": true, "unknown": true}
-	validIDs := map[string]bool{"sip": true, "gatekeeper": true, "filevault": true, "firewall": true, "autologin": true, "osupdate": true}
+	validStatuses := map[string]bool{}
+	for _, s := range statuses {
+		validStatuses[s] = true
+	}
 
 	for _, check := range report.Checks {
-		if !validIDs[check.ID] {
+		if _, ok := checks.Lookup(check.ID); !ok {
 			return fmt.Errorf("invalid check ID: %s", check.ID)
 		}
 		if !validStatuses[check.Status] {
 			return fmt.Errorf("invalid status: %s", check.Status)
 		}
-		if check.Score < 0 || check.Score > 20 {
-			return fmt.Errorf("check score must be between 0 and 20, got %d for %s", check.Score, check.ID)
+		if check.Severity != "" && !checks.IsSeverity(check.Severity) {
+			return fmt.Errorf("invalid severity: %s", check.Severity)
+		}
+		for _, c := range check.Controls {
+			if c.Framework == "" || c.ID == "" {
+				return fmt.Errorf("control framework and id are required for %s", check.ID)
+			}
+		}
+		if check.Error != nil && !isErrorKind(check.Error.Kind) {
+			return fmt.Errorf("invalid error kind %q for %s", check.Error.Kind, check.ID)
+		}
+		if check.DurationMs < 0 {
+			return fmt.Errorf("duration_ms must not be negative for %s", check.ID)
+		}
+		if check.Score < 0 || check.Score > check.Weight {
+			return fmt.Errorf("check score must be between 0 and %d, got %d for %s", check.Weight, check.Score, check.ID)
+		}
+	}
+
+	for _, c := range report.Commands {
+		if len(c.Argv) == 0 {
+			return fmt.Errorf("command argv is required")
+		}
+		if c.Check != "" {
+			if _, ok := checks.Lookup(c.Check); !ok {
+				return fmt.Errorf("invalid check ID %q for command %s", c.Check, c.Argv[0])
+			}
+		}
+		if c.ErrorKind != "" && !isErrorKind(c.ErrorKind) {
+			return fmt.Errorf("invalid error kind %q for command %s", c.ErrorKind, c.Argv[0])
 		}
 	}
 
@@ -32,7 +32,7 @@ func TestJSONSchemaGenerator_GenerateReportSchema(t *testing.T) {
 	}
 
 	// Check required fields
-	requiredFields := []string{"version", "host", "score", "checks"}
+	requiredFields := []string{"version", "host", "score", "max_score", "earned_score", "checks"}
 	for _, field := range requiredFields {
 		if properties[field] == nil {
 			t.Errorf("Schema missing required field: %s", field)
@@ -54,13 +54,17 @@ func TestJSONSchemaGenerator_ValidateReport(t *testing.T) {
 				Build:   "23C71",
 			},
 		},
-		Score: 85,
+		Score:       85,
+		MaxScore:    100,
+		EarnedScore: 85,
+		Grade:       "B",
 		Checks: []types.CheckResult{
 			{
 				ID:     "sip",
 				Title:  "System Integrity Protection enabled",
 				Status: "pass",
 				Score:  20,
+				Weight: 20,
 			},
 		},
 	}
@@ -76,6 +80,27 @@ func TestJSONSchemaGenerator_ValidateReport(t *testing.T) {
 		t.Error("Invalid score should fail validation")
 	}
 
+	// Invalid report - grade does not match score
+	invalidReport = validReport
+	invalidReport.Grade = "A"
+	if err := generator.ValidateReport(invalidReport); err == nil {
+		t.Error("Grade inconsistent with score should fail validation")
+	}
+
+	// Invalid report - check score above its weight
+	invalidReport = validReport
+	invalidReport.Checks = []types.CheckResult{{ID: "sip", Title: "SIP", Status: "pass", Score: 30, Weight: 20}}
+	if err := generator.ValidateReport(invalidReport); err == nil {
+		t.Error("Check score above weight should fail validation")
+	}
+
+	// Invalid report - command attributed to an unknown check
+	invalidReport = validReport
+	invalidReport.Commands = []types.CommandRecord{{Check: "invalid", CommandRun: types.CommandRun{Argv: []string{"/usr/bin/true"}}}}
+	if err := generator.ValidateReport(invalidReport); err == nil {
+		t.Error("Command with invalid check ID should fail validation")
+	}
+
 	// Invalid report - invalid check ID
 	invalidReport = validReport
 	invalidReport.Checks[0].ID = "invalid"
@@ -122,12 +147,16 @@ func TestJSONSchemaValidator_ValidateJSON(t *testing.T) {
 			}
 		},
 		"score": 85,
+		"max_score": 100,
+		"earned_score": 85,
+		"grade": "B",
 		"checks": [
 			{
 				"id": "sip",
 				"title": "System Integrity Protection enabled",
 				"status": "pass",
-				"score": 20
+				"score": 20,
+				"weight": 20
 			}
 		]
 	}`
@@ -0,0 +1,180 @@
+package scoring
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/samuraidays/macinsight/pkg/types"
+)
+
+// unknown 結果の扱い（ポリシー）
+const (
+	UnknownPartial = "partial" // 重みの半分を付与（従来の挙動）
+	UnknownFail    = "fail"    // 0 点として扱う
+	UnknownExclude = "exclude" // 採点対象から外す
+)
+
+// Policies は指定可能な unknown ポリシー一覧
+var Policies = []string{UnknownPartial, UnknownFail, UnknownExclude}
+
+// ValidatePolicy は unknown ポリシー名を検証する
+func ValidatePolicy(p string) error {
+	for _, v := range Policies {
+		if p == v {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid unknown policy %q (want one of %v)", p, Policies)
+}
+
+// Points はステータスと重みから獲得点を返す
+// applicable が false の場合、そのチェックは採点対象外
+func Points(status string, weight int, unknownPolicy string) (earned int, applicable bool) {
+	switch status {
+	case "pass":
+		return weight, true
+	case "warn":
+		return weight / 2, true
+	case "fail":
+		return 0, true
+	case "unknown":
+		switch unknownPolicy {
+		case UnknownFail:
+			return 0, true
+		case UnknownExclude:
+			return 0, false
+		default:
+			return weight / 2, true
+		}
+	default:
+		// not_applicable / waived など
+		return 0, false
+	}
+}
+
+// Summary は採点結果
+type Summary struct {
+	Score       int                   // 0〜100（採点対象の重みに対する割合）
+	MaxScore    int                   // 採点対象チェックの重み合計
+	EarnedScore int                   // 獲得点の合計
+	Grade       string                // Score に対応する評価（A〜F）
+	Categories  []types.CategoryScore // カテゴリ別のサブスコア（カテゴリ名順）
+}
+
+// 評価の下限スコア（これ未満は F）
+var gradeThresholds = []struct {
+	min   int
+	grade string
+}{
+	{90, "A"},
+	{80, "B"},
+	{70, "C"},
+	{60, "D"},
+}
+
+// Grade はスコア（0〜100）を A〜F の評価にする
+func Grade(score int) string {
+	for _, t := range gradeThresholds {
+		if score >= t.min {
+			return t.grade
+		}
+	}
+	return "F"
+}
+
+// Apply は各結果の Score を付け直し、全体の採点結果を返す
+// results[i].Weight は呼び出し側で設定済みであること
+func Apply(results []types.CheckResult, unknownPolicy string) Summary {
+	var s Summary
+	byCategory := map[string]*types.CategoryScore{}
+	for i := range results {
+		earned, ok := Points(results[i].Status, results[i].Weight, unknownPolicy)
+		results[i].Score = earned
+		if !ok {
+			continue
+		}
+		s.MaxScore += results[i].Weight
+		s.EarnedScore += earned
+
+		name := results[i].Category
+		if name == "" {
+			name = "uncategorized"
+		}
+		cs, ok := byCategory[name]
+		if !ok {
+			cs = &types.CategoryScore{Category: name}
+			byCategory[name] = cs
+		}
+		cs.MaxScore += results[i].Weight
+		cs.EarnedScore += earned
+	}
+	s.Score = Percent(s.EarnedScore, s.MaxScore)
+	s.Grade = Grade(s.Score)
+
+	for _, cs := range byCategory {
+		cs.Score = Percent(cs.EarnedScore, cs.MaxScore)
+		s.Categories = append(s.Categories, *cs)
+	}
+	sort.Slice(s.Categories, func(i, j int) bool { return s.Categories[i].Category < s.Categories[j].Category })
+	return s
+}
+
+// Compliance はフレームワークごとの合格率を返す（フレームワーク名順）
+// not_applicable / waived の結果は集計しない
+func Compliance(results []types.CheckResult) []types.ComplianceSummary {
+	byFramework := map[string]*types.ComplianceSummary{}
+	var names []string
+	for _, r := range results {
+		switch r.Status {
+		case "pass", "warn", "fail", "unknown":
+		default:
+			continue
+		}
+		counted := map[string]bool{} // 1つのチェックはフレームワークごとに1回だけ数える
+		for _, c := range r.Controls {
+			cs, ok := byFramework[c.Framework]
+			if !ok {
+				cs = &types.ComplianceSummary{Framework: c.Framework}
+				byFramework[c.Framework] = cs
+				names = append(names, c.Framework)
+			}
+			if !containsString(cs.Controls, c.ID) {
+				cs.Controls = append(cs.Controls, c.ID)
+			}
+			if counted[c.Framework] {
+				continue
+			}
+			counted[c.Framework] = true
+			cs.Total++
+			if r.Status == "pass" {
+				cs.Passed++
+			}
+		}
+	}
+
+	sort.Strings(names)
+	list := make([]types.ComplianceSummary, 0, len(names))
+	for _, n := range names {
+		cs := byFramework[n]
+		cs.PassRate = Percent(cs.Passed, cs.Total)
+		list = append(list, *cs)
+	}
+	return list
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Percent は earned/total を 0〜100 の整数（四捨五入）にする
+func Percent(earned, total int) int {
+	if total <= 0 {
+		return 0
+	}
+	return (earned*100 + total/2) / total
+}
@@ -0,0 +1,118 @@
+package scoring
+
+import (
+	"testing"
+
+	"github.com/samuraidays/macinsight/pkg/types"
+)
+
+func TestApply_NormalizesToApplicableWeight(t *testing.T) {
+	results := []types.CheckResult{
+		{ID: "filevault", Status: "pass", Weight: 20},
+	}
+	sum := Apply(results, UnknownPartial)
+	if sum.Score != 100 || sum.MaxScore != 20 || sum.EarnedScore != 20 {
+		t.Fatalf("single passing check should score 100, got %+v", sum)
+	}
+}
+
+func TestApply_ExcludesNotApplicable(t *testing.T) {
+	results := []types.CheckResult{
+		{ID: "a", Status: "pass", Weight: 10},
+		{ID: "b", Status: "fail", Weight: 10},
+		{ID: "c", Status: "not_applicable", Weight: 80},
+	}
+	sum := Apply(results, UnknownPartial)
+	if sum.Score != 50 || sum.MaxScore != 20 {
+		t.Fatalf("not_applicable should be excluded, got %+v", sum)
+	}
+}
+
+func TestApply_UnknownPolicies(t *testing.T) {
+	cases := []struct {
+		policy string
+		score  int
+		max    int
+	}{
+		{UnknownPartial, 75, 40},
+		{UnknownFail, 50, 40},
+		{UnknownExclude, 100, 20},
+	}
+	for _, c := range cases {
+		results := []types.CheckResult{
+			{ID: "a", Status: "pass", Weight: 20},
+			{ID: "b", Status: "unknown", Weight: 20},
+		}
+		sum := Apply(results, c.policy)
+		if sum.Score != c.score || sum.MaxScore != c.max {
+			t.Fatalf("policy %s: got %+v, want score=%d max=%d", c.policy, sum, c.score, c.max)
+		}
+	}
+}
+
+func TestValidatePolicy(t *testing.T) {
+	if err := ValidatePolicy("partial"); err != nil {
+		t.Fatalf("partial should be valid: %v", err)
+	}
+	if err := ValidatePolicy("half"); err == nil {
+		t.Fatal("unknown policy name should be rejected")
+	}
+}
+
+func TestCompliance_PassRateByFramework(t *testing.T) {
+	nist := func(id string) types.Control { return types.Control{Framework: "NIST", ID: id} }
+	results := []types.CheckResult{
+		{ID: "a", Status: "pass", Controls: []types.Control{nist("SC-28"), nist("SC-28(1)"), {Framework: "CIS", ID: "3.6"}}},
+		{ID: "b", Status: "fail", Controls: []types.Control{nist("SI-2")}},
+		{ID: "c", Status: "waived", Controls: []types.Control{nist("IA-2")}},
+		{ID: "d", Status: "not_applicable", Controls: []types.Control{{Framework: "ISO", ID: "A.8.5"}}},
+		{ID: "e", Status: "pass"},
+	}
+	got := Compliance(results)
+	if len(got) != 2 || got[0].Framework != "CIS" || got[1].Framework != "NIST" {
+		t.Fatalf("frameworks = %+v, want CIS and NIST only", got)
+	}
+	if got[0].Passed != 1 || got[0].Total != 1 || got[0].PassRate != 100 {
+		t.Errorf("CIS = %+v", got[0])
+	}
+	// 同じチェックの複数管理策は1件として数える
+	if got[1].Passed != 1 || got[1].Total != 2 || got[1].PassRate != 50 {
+		t.Errorf("NIST = %+v", got[1])
+	}
+	if len(got[1].Controls) != 3 {
+		t.Errorf("NIST controls = %v, want SC-28, SC-28(1), SI-2", got[1].Controls)
+	}
+}
+
+func TestApply_CategorySubScoresAndGrade(t *testing.T) {
+	results := []types.CheckResult{
+		{ID: "a", Status: "pass", Weight: 20, Category: "network"},
+		{ID: "b", Status: "fail", Weight: 20, Category: "encryption"},
+		{ID: "c", Status: "warn", Weight: 20, Category: "encryption"},
+		{ID: "d", Status: "not_applicable", Weight: 20, Category: "patching"},
+	}
+	sum := Apply(results, UnknownPartial)
+	if sum.Score != 50 || sum.Grade != "F" {
+		t.Fatalf("score=%d grade=%s, want 50 F", sum.Score, sum.Grade)
+	}
+	want := []types.CategoryScore{
+		{Category: "encryption", Score: 25, MaxScore: 40, EarnedScore: 10},
+		{Category: "network", Score: 100, MaxScore: 20, EarnedScore: 20},
+	}
+	if len(sum.Categories) != len(want) {
+		t.Fatalf("categories = %+v, want %+v", sum.Categories, want)
+	}
+	for i := range want {
+		if sum.Categories[i] != want[i] {
+			t.Errorf("categories[%d] = %+v, want %+v", i, sum.Categories[i], want[i])
+		}
+	}
+}
+
+func TestGrade(t *testing.T) {
+	for score, want := range map[int]string{100: "A", 90: "A", 89: "B", 80: "B", 75: "C", 60: "D", 59: "F", 0: "F"} {
+		if got := Grade(score); got != want {
+			t.Errorf("Grade(%d) = %s, want %s", score, got, want)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package waiver
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/samuraidays/macinsight/internal/checks"
+	"github.com/samuraidays/macinsight/pkg/types"
+)
+
+// 期限日の書式
+const dateLayout = "2006-01-02"
+
+// Waiver は特定チェックの例外承認1件
+type Waiver struct {
+	Check         string `yaml:"check"`              // 対象チェックID
+	Hostname      string `yaml:"hostname,omitempty"` // 対象ホスト（空なら全ホスト）
+	Serial        string `yaml:"serial,omitempty"`   // 対象シリアル番号（空なら全端末）
+	Owner         string `yaml:"owner"`              // 承認責任者
+	Justification string `yaml:"justification"`      // 理由
+	Expires       string `yaml:"expires"`            // 期限日（YYYY-MM-DD、当日まで有効）
+
+	expires time.Time
+}
+
+type file struct {
+	Waivers []Waiver `yaml:"waivers"`
+}
+
+// Load は例外ファイルを読み込んで検証する
+func Load(path string) ([]Waiver, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read waivers %s: %w", path, err)
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	var f file
+	if err := dec.Decode(&f); err != nil {
+		return nil, fmt.Errorf("failed to parse waivers %s: %w", path, err)
+	}
+
+	for i := range f.Waivers {
+		if err := f.Waivers[i].validate(); err != nil {
+			return nil, fmt.Errorf("invalid waiver #%d in %s: %w", i+1, path, err)
+		}
+	}
+	return f.Waivers, nil
+}
+
+func (w *Waiver) validate() error {
+	if w.Check == "" {
+		return fmt.Errorf("check is required")
+	}
+	if w.Owner == "" {
+		return fmt.Errorf("owner is required")
+	}
+	if w.Justification == "" {
+		return fmt.Errorf("justification is required")
+	}
+	t, err := time.ParseInLocation(dateLayout, w.Expires, time.Local)
+	if err != nil {
+		return fmt.Errorf("expires must be YYYY-MM-DD: %w", err)
+	}
+	// 期限日の終わりまで有効
+	w.expires = t.AddDate(0, 0, 1)
+	return nil
+}
+
+// Expired は now 時点で期限切れか返す
+func (w Waiver) Expired(now time.Time) bool {
+	return !now.Before(w.expires)
+}
+
+// matches はチェックとホストが対象に一致するか返す
+func (w Waiver) matches(id string, host types.HostInfo) bool {
+	if w.Check != id {
+		return false
+	}
+	if w.Hostname != "" && !strings.EqualFold(w.Hostname, host.Hostname) {
+		return false
+	}
+	if w.Serial != "" && !strings.EqualFold(w.Serial, host.Serial) {
+		return false
+	}
+	return true
+}
+
+// Validate は例外の対象チェックが登録済みか検証する（ルール・プラグインの登録後に呼ぶ）
+// チェックIDの誤記で例外が黙って無視されるのを防ぐ
+func Validate(waivers []Waiver) error {
+	for i, w := range waivers {
+		if _, ok := checks.Lookup(w.Check); !ok {
+			return fmt.Errorf("invalid waiver #%d: unknown check %q", i+1, w.Check)
+		}
+	}
+	return nil
+}
+
+// 例外承認の対象になるステータス（評価して見つかった指摘のみ）
+// unknown / skipped / cancelled は評価できていないため、例外で隠さない
+var waivable = map[string]bool{"fail": true, "warn": true}
+
+// Apply は fail / warn の結果のうち有効な例外に一致するものを waived にする
+// 期限切れの例外は元のステータスのまま、その旨を evidence に残す
+func Apply(results []types.CheckResult, host types.HostInfo, waivers []Waiver, now time.Time) {
+	for i := range results {
+		cr := &results[i]
+		if !waivable[cr.Status] {
+			continue
+		}
+		for _, w := range waivers {
+			if !w.matches(cr.ID, host) {
+				continue
+			}
+			if cr.Evidence == nil {
+				cr.Evidence = map[string]string{}
+			}
+			if w.Expired(now) {
+				cr.Evidence["waiver"] = fmt.Sprintf("expired on %s (owner: %s)", w.Expires, w.Owner)
+				continue
+			}
+			cr.Evidence["waiver"] = fmt.Sprintf("waived until %s (original status: %s)", w.Expires, cr.Status)
+			cr.Evidence["waiver_owner"] = w.Owner
+			cr.Evidence["waiver_justification"] = w.Justification
+			cr.Status = "waived"
+			break
+		}
+	}
+}
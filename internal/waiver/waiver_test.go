@@ -0,0 +1,137 @@
+package waiver
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/samuraidays/macinsight/pkg/types"
+)
+
+func load(t *testing.T, body string) ([]Waiver, error) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "waivers.yaml")
+	if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return Load(path)
+}
+
+const sample = `
+waivers:
+  - check: sip
+    hostname: kdev-01
+    owner: alice
+    justification: kernel extension development
+    expires: 2026-12-31
+  - check: firewall
+    serial: C02LAB0001
+    owner: bob
+    justification: lab machine behind network firewall
+    expires: 2026-01-31
+`
+
+func TestApply_WaivesMatchingFailures(t *testing.T) {
+	ws, err := load(t, sample)
+	if err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.Local)
+
+	results := []types.CheckResult{
+		{ID: "sip", Status: "fail"},
+		{ID: "gatekeeper", Status: "fail"},
+	}
+	Apply(results, types.HostInfo{Hostname: "KDEV-01"}, ws, now)
+	if results[0].Status != "waived" || results[0].Evidence["waiver_owner"] != "alice" {
+		t.Fatalf("sip should be waived, got %+v", results[0])
+	}
+	if results[1].Status != "fail" {
+		t.Fatalf("gatekeeper has no waiver, got %s", results[1].Status)
+	}
+
+	// ホスト名が一致しなければ適用しない
+	results = []types.CheckResult{{ID: "sip", Status: "fail"}}
+	Apply(results, types.HostInfo{Hostname: "other"}, ws, now)
+	if results[0].Status != "fail" {
+		t.Fatalf("waiver for another host should not apply, got %s", results[0].Status)
+	}
+}
+
+func TestApply_ExpiredWaiverKeepsFailure(t *testing.T) {
+	ws, err := load(t, sample)
+	if err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+	host := types.HostInfo{Hostname: "lab", Serial: "C02LAB0001"}
+
+	// 期限日当日は有効
+	results := []types.CheckResult{{ID: "firewall", Status: "fail"}}
+	Apply(results, host, ws, time.Date(2026, 1, 31, 23, 0, 0, 0, time.Local))
+	if results[0].Status != "waived" {
+		t.Fatalf("waiver should be valid through its expiry date, got %s", results[0].Status)
+	}
+
+	results = []types.CheckResult{{ID: "firewall", Status: "fail"}}
+	Apply(results, host, ws, time.Date(2026, 2, 1, 0, 0, 0, 0, time.Local))
+	if results[0].Status != "fail" || results[0].Evidence["waiver"] == "" {
+		t.Fatalf("expired waiver should leave fail with a note, got %+v", results[0])
+	}
+}
+
+func TestLoad_RequiresOwnerJustificationAndExpiry(t *testing.T) {
+	for _, body := range []string{
+		"waivers:\n  - check: sip\n    justification: x\n    expires: 2026-12-31\n",
+		"waivers:\n  - check: sip\n    owner: a\n    expires: 2026-12-31\n",
+		"waivers:\n  - check: sip\n    owner: a\n    justification: x\n",
+		"waivers:\n  - check: sip\n    owner: a\n    justification: x\n    expires: 31/12/2026\n",
+	} {
+		if _, err := load(t, body); err == nil {
+			t.Errorf("waiver should be rejected: %q", body)
+		}
+	}
+}
+
+func TestApply_DoesNotWaiveUnevaluatedResults(t *testing.T) {
+	ws, err := load(t, sample)
+	if err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.Local)
+	host := types.HostInfo{Hostname: "kdev-01"}
+
+	// 評価できなかった結果は例外で隠さない（終了コード 3 の判定に残す）
+	for _, status := range []string{"unknown", "skipped", "cancelled", "not_applicable", "pass"} {
+		results := []types.CheckResult{{ID: "sip", Status: status}}
+		Apply(results, host, ws, now)
+		if results[0].Status != status || results[0].Evidence["waiver"] != "" {
+			t.Errorf("%s result should not be waived, got %+v", status, results[0])
+		}
+	}
+
+	results := []types.CheckResult{{ID: "sip", Status: "warn"}}
+	Apply(results, host, ws, now)
+	if results[0].Status != "waived" {
+		t.Errorf("warn result should be waived, got %s", results[0].Status)
+	}
+}
+
+func TestValidate_RejectsUnknownCheck(t *testing.T) {
+	ws, err := load(t, sample)
+	if err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+	if err := Validate(ws); err != nil {
+		t.Fatalf("waivers for built-in checks should be valid: %v", err)
+	}
+
+	ws, err = load(t, "waivers:\n  - check: filevalt\n    owner: a\n    justification: x\n    expires: 2026-12-31\n")
+	if err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+	if err := Validate(ws); err == nil || !strings.Contains(err.Error(), "filevalt") {
+		t.Fatalf("expected unknown check error, got %v", err)
+	}
+}
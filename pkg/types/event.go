@@ -0,0 +1,60 @@
+package types
+
+import "time"
+
+// 監査の進行イベントの種類
+const (
+	EventRunStarted    = "run_started"
+	EventCheckStarted  = "check_started"
+	EventCheckFinished = "check_finished"
+	EventRunFinished   = "run_finished"
+)
+
+// 監査の進行イベント（--format ndjson で1行1件出力）
+type Event struct {
+	Event  string       `json:"event"`            // run_started | check_started | check_finished | run_finished
+	Time   time.Time    `json:"time"`             // イベントの発生時刻
+	ID     string       `json:"id,omitempty"`     // check_started / check_finished のチェックID
+	Result *CheckResult `json:"result,omitempty"` // check_finished の結果
+	Run    *RunInfo     `json:"run,omitempty"`    // run_started の監査情報
+	Totals *RunTotals   `json:"totals,omitempty"` // run_finished の集計
+}
+
+// 監査開始時点で分かっている情報
+type RunInfo struct {
+	Version    string   `json:"version"`
+	Host       HostInfo `json:"host"`
+	Profile    string   `json:"profile,omitempty"`
+	Privileged bool     `json:"privileged"`
+	Checks     []string `json:"checks"` // 結果が出る予定のチェックID（実行順ではなく登録順）
+}
+
+// 監査終了時の集計
+type RunTotals struct {
+	DurationMs  int64               `json:"duration_ms"`
+	Score       int                 `json:"score"`
+	MaxScore    int                 `json:"max_score"`
+	EarnedScore int                 `json:"earned_score"`
+	Grade       string              `json:"grade"`
+	Statuses    map[string]int      `json:"statuses"` // ステータスごとのチェック数
+	Categories  []CategoryScore     `json:"categories,omitempty"`
+	Compliance  []ComplianceSummary `json:"compliance,omitempty"`
+}
+
+// Totals はレポートから run_finished の集計を作る
+func (r Report) Totals() RunTotals {
+	statuses := map[string]int{}
+	for _, c := range r.Checks {
+		statuses[c.Status]++
+	}
+	return RunTotals{
+		DurationMs:  r.DurationMs,
+		Score:       r.Score,
+		MaxScore:    r.MaxScore,
+		EarnedScore: r.EarnedScore,
+		Grade:       r.Grade,
+		Statuses:    statuses,
+		Categories:  r.Categories,
+		Compliance:  r.Compliance,
+	}
+}
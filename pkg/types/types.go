@@ -1,18 +1,79 @@
 package types
 
+import "time"
+
 // 各チェックの結果を表す構造体
 type CheckResult struct {
 	ID             string            `json:"id"`                       // 例: "gatekeeper"
 	Title          string            `json:"title"`                    // 例: "Gatekeeper enabled"
-	Status         string            `json:"status"`                   // "pass" | "fail" | "unknown"
+	Status         string            `json:"status"`                   // "pass" | "fail" | "warn" | "unknown" | "not_applicable" | "waived" | "cancelled" | "skipped"
+	Reason         string            `json:"reason,omitempty"`         // 評価しなかった理由（skipped / cancelled / not_applicable）
 	Score          int               `json:"score"`                    // このチェックに対して付与された点数
+	Weight         int               `json:"weight"`                   // このチェックの配点（満点）
+	Severity       string            `json:"severity,omitempty"`       // "critical" | "high" | "medium" | "low" | "info"
+	Category       string            `json:"category,omitempty"`       // 例: "encryption"
+	Controls       []Control         `json:"controls,omitempty"`       // 対応するコンプライアンス管理策
 	Evidence       map[string]string `json:"evidence,omitempty"`       // コマンド出力などの証跡
 	Recommendation string            `json:"recommendation,omitempty"` // 改善提案（v0.1は任意）
+	Error          *CheckError       `json:"error,omitempty"`          // コマンド実行失敗の分類と説明
+	StartedAt      *time.Time        `json:"started_at,omitempty"`     // チェックの開始時刻（実行しなかった場合は無し）
+	DurationMs     int64             `json:"duration_ms"`              // チェックの所要時間（ミリ秒）
+	Commands       []CommandRun      `json:"commands,omitempty"`       // チェック中に実行したコマンド
+}
+
+// チェック実行中のエラー（分類は executil.ErrorKind）
+type CheckError struct {
+	Kind    string `json:"kind"`    // not_found | permission_denied | timeout | exit_status | killed | error
+	Message string `json:"message"` // 例: "fdesetup requires root: ..."
+}
+
+// チェック中に実行したコマンド1件
+type CommandRun struct {
+	Argv        []string `json:"argv"`
+	CommandLine string   `json:"command_line"`         // 上書きした環境変数・作業ディレクトリを含む実効コマンドライン
+	ExitCode    int      `json:"exit_code"`            // 起動できなかった場合は -1
+	DurationMs  int64    `json:"duration_ms"`          // 実行時間（ミリ秒）
+	Stderr      string   `json:"stderr,omitempty"`     // 標準エラー出力（長い場合は切り詰め）
+	Error       string   `json:"error,omitempty"`      // 起動失敗・タイムアウトなど
+	ErrorKind   string   `json:"error_kind,omitempty"` // Error の分類
+	Cached      bool     `json:"cached,omitempty"`     // 同じ監査内の実行結果を再利用したか
+	Truncated   bool     `json:"truncated,omitempty"`  // 出力が上限を超えて切り捨てられたか
+}
+
+// 監査中に実行したコマンド1件（監査証跡）
+type CommandRecord struct {
+	Check     string    `json:"check,omitempty"` // 実行したチェックのID（ホスト情報の取得は空）
+	StartedAt time.Time `json:"started_at"`      // 実行の開始時刻
+	CommandRun
+}
+
+// フレームワークの管理策ID（例: NIST SP 800-53 Rev. 5 の "SC-28"）
+type Control struct {
+	Framework string `json:"framework"`
+	ID        string `json:"id"`
+}
+
+// フレームワークごとの準拠状況
+type ComplianceSummary struct {
+	Framework string   `json:"framework"`
+	Controls  []string `json:"controls"`  // 評価対象になった管理策ID
+	Passed    int      `json:"passed"`    // 合格したチェック数
+	Total     int      `json:"total"`     // 評価対象のチェック数（pass / warn / fail / unknown のみ）
+	PassRate  int      `json:"pass_rate"` // 0〜100
+}
+
+// カテゴリ別の採点結果
+type CategoryScore struct {
+	Category    string `json:"category"`
+	Score       int    `json:"score"`        // 0〜100（カテゴリ内の配点に対する割合）
+	MaxScore    int    `json:"max_score"`    // カテゴリ内の採点対象チェックの配点合計
+	EarnedScore int    `json:"earned_score"` // カテゴリ内の獲得点の合計
 }
 
 // ホスト情報（OSなど）
 type HostInfo struct {
 	Hostname string `json:"hostname"`
+	Serial   string `json:"serial,omitempty"` // ハードウェアのシリアル番号
 	OS       OSInfo `json:"os"`
 }
 
@@ -24,8 +85,19 @@ type OSInfo struct {
 
 // 監査レポートの全体構造
 type Report struct {
-	Version string        `json:"version"` // macinsight のバージョン
-	Host    HostInfo      `json:"host"`
-	Score   int           `json:"score"` // 0〜100
-	Checks  []CheckResult `json:"checks"`
+	Version     string              `json:"version"` // macinsight のバージョン
+	Host        HostInfo            `json:"host"`
+	Profile     string              `json:"profile,omitempty"`    // 使用したプロファイル名
+	StartedAt   time.Time           `json:"started_at"`           // 監査の開始時刻
+	FinishedAt  time.Time           `json:"finished_at"`          // 監査の終了時刻
+	DurationMs  int64               `json:"duration_ms"`          // 監査全体の所要時間（ミリ秒）
+	Privileged  bool                `json:"privileged"`           // root 権限で実行したか
+	Score       int                 `json:"score"`                // 0〜100（採点対象の配点に対する割合）
+	MaxScore    int                 `json:"max_score"`            // 採点対象チェックの配点合計
+	EarnedScore int                 `json:"earned_score"`         // 獲得点の合計
+	Grade       string              `json:"grade"`                // A〜F（score から算出）
+	Categories  []CategoryScore     `json:"categories,omitempty"` // カテゴリ別のサブスコア
+	Compliance  []ComplianceSummary `json:"compliance,omitempty"` // フレームワーク別の合格率
+	Checks      []CheckResult       `json:"checks"`
+	Commands    []CommandRecord     `json:"commands,omitempty"` // 監査中に実行した全コマンド（ホスト情報・チェック順）
 }